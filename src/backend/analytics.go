@@ -0,0 +1,100 @@
+package main
+
+import (
+	"backend/util"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// defaultLeaderboardLimit jumlah elemen leaderboard kalo ?limit= kosong
+const defaultLeaderboardLimit = 20
+
+// ElementAnalyticsResponse analytics satu elemen beserta elemen kritisnya
+type ElementAnalyticsResponse struct {
+	util.ElementAnalytics
+	Dataset  string   `json:"dataset"`
+	Policy   string   `json:"policy"`
+	Critical []string `json:"critical"` // Elemen yang ada di setiap resep elemen ini
+}
+
+// LeaderboardResponse elemen teratas satu dataset menurut satu metrik
+type LeaderboardResponse struct {
+	Dataset  string                  `json:"dataset"`
+	Policy   string                  `json:"policy"`
+	Metric   string                  `json:"metric"`
+	Elements []util.ElementAnalytics `json:"elements"`
+}
+
+// analyticsTarget dataset dan policy dari query ?dataset= dan ?policy=, kayak verifyHandler
+func analyticsTarget(r *http.Request) (*util.Dataset, util.ValidityPolicy, error) {
+	policy, err := util.PolicyByName(r.URL.Query().Get("policy"))
+	if err != nil {
+		return nil, nil, err
+	}
+	ds, err := datasetByName(r.URL.Query().Get("dataset"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return ds, policy, nil
+}
+
+// analyticsHandler analytics satu elemen (GET /api/analytics/{element})
+func analyticsHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	ds, policy, err := analyticsTarget(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	element := r.PathValue("element")
+	if !ds.Has(element) {
+		http.Error(w, fmt.Sprintf("unknown element %q", element), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, ElementAnalyticsResponse{
+		ElementAnalytics: *util.Analytics(ds, policy).Elements[element],
+		Dataset:          ds.Name,
+		Policy:           policy.Name(),
+		Critical:         util.CriticalElements(element, ds, policy),
+	})
+}
+
+// leaderboardHandler elemen teratas satu dataset
+// (GET /api/analytics?metric=difficulty|usage|betweenness&limit=20)
+func leaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	ds, policy, err := analyticsTarget(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultLeaderboardLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			http.Error(w, fmt.Sprintf("invalid limit %q", raw), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	metric := r.URL.Query().Get("metric")
+	if metric == "" {
+		metric = util.LeaderboardDifficulty
+	}
+	board, err := util.Analytics(ds, policy).Leaderboard(metric, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, LeaderboardResponse{Dataset: ds.Name, Policy: policy.Name(), Metric: metric, Elements: board})
+}
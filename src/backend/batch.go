@@ -0,0 +1,454 @@
+package main
+
+import (
+	"backend/util"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Batas antrian batch: job yang nunggu, job yang jalan barengan, dan job yang
+// disimpen (yang udah selesai dibuang duluan). Hasil job disimpen di memori
+// sampai dibuang, jadi resep per target dan total ukuran hasilnya juga dibatesin;
+// paling banyak maxBatchJobs * maxBatchResultSize yang ketahan di memori.
+const (
+	maxPendingBatches  = 16
+	batchRunners       = 2
+	maxBatchJobs       = 32
+	maxBatchTargets    = 10000
+	maxBatchRecipes    = 20
+	maxBatchResultSize = 16 << 20 // Byte NDJSON per job
+	batchTargetTimeout = 30 * time.Second
+)
+
+// Status job batch
+const (
+	batchQueued    = "queued"
+	batchRunning   = "running"
+	batchDone      = "done"
+	batchCancelled = "cancelled"
+	batchFailed    = "failed" // Hasilnya ngelewatin maxBatchResultSize
+)
+
+// BatchRequest banyak target dengan opsi pencarian yang sama. Field namaResep
+// diabaikan; all: true berarti semua elemen non-dasar di dataset.
+type BatchRequest struct {
+	Targets []string `json:"targets"`
+	All     bool     `json:"all,omitempty"`
+	SearchRequest
+}
+
+// BatchStatus progress job, dikirim waktu job dibikin dan waktu di-poll
+type BatchStatus struct {
+	ID         string     `json:"jobId"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Completed  int        `json:"completed"` // Termasuk yang gagal
+	Failed     int        `json:"failed"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// batchResult satu baris NDJSON hasil batch
+type batchResult struct {
+	Target      string           `json:"target"`
+	TreeData    []*util.Node     `json:"treeData"`
+	DAGData     []util.RecipeDAG `json:"dagData,omitempty"`
+	TimeTaken   string           `json:"timetaken"`
+	NodeVisited int              `json:"node_visited"`
+	Stats       util.SearchStats `json:"stats"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// batchJob satu request /api/batch. Field di bawah mu diubah runner sambil di-poll handler.
+type batchJob struct {
+	id       string
+	req      BatchRequest
+	targets  []string
+	ds       *util.Dataset
+	policy   util.ValidityPolicy
+	searcher util.Searcher // nil buat dataset dengan reaction
+	budget   util.MemoryBudget
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	mu       sync.Mutex
+	status   string
+	failed   int
+	results  [][]byte // Baris NDJSON, urut selesai
+	size     int      // Total byte results
+	err      string
+	created  time.Time
+	started  time.Time
+	finished time.Time
+}
+
+// Status snapshot progress job
+func (job *batchJob) Status() BatchStatus {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	status := BatchStatus{
+		ID:        job.id,
+		Status:    job.status,
+		Total:     len(job.targets),
+		Completed: len(job.results),
+		Failed:    job.failed,
+		CreatedAt: job.created,
+		Error:     job.err,
+	}
+	if !job.started.IsZero() {
+		started := job.started
+		status.StartedAt = &started
+	}
+	if !job.finished.IsZero() {
+		finished := job.finished
+		status.FinishedAt = &finished
+	}
+	return status
+}
+
+// finish nandain job selesai kalo belum, ngembaliin false kalo udah selesai duluan
+func (job *batchJob) finish(status string) bool {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.status == batchDone || job.status == batchCancelled || job.status == batchFailed {
+		return false
+	}
+	job.status = status
+	job.finished = time.Now()
+	return true
+}
+
+// run nyari resep tiap target satu-satu. Pembatalan ikut diterusin ke pencarian
+// Multiple*, jadi target yang lagi dicari berhenti dan gak dimasukin ke hasil.
+func (job *batchJob) run() {
+	job.mu.Lock()
+	if job.status != batchQueued {
+		job.mu.Unlock()
+		return
+	}
+	job.status = batchRunning
+	job.started = time.Now()
+	job.mu.Unlock()
+
+	for _, target := range job.targets {
+		if job.ctx.Err() != nil {
+			job.finish(batchCancelled)
+			return
+		}
+
+		result := job.search(target)
+		if job.ctx.Err() != nil {
+			job.finish(batchCancelled)
+			return
+		}
+		line, err := json.Marshal(result)
+		if err != nil {
+			log.Printf("Batch %s: error encoding result for %q: %v", job.id, target, err)
+			line, _ = json.Marshal(batchResult{Target: target, Error: "internal error"})
+			result.Error = "internal error"
+		}
+
+		job.mu.Lock()
+		if job.size+len(line)+1 > maxBatchResultSize {
+			// Hasil yang udah ada tetap bisa di-download
+			job.err = fmt.Sprintf("results exceed %d bytes; use fewer targets or a smaller maksimalResep", maxBatchResultSize)
+			job.mu.Unlock()
+			job.finish(batchFailed)
+			return
+		}
+		job.results = append(job.results, line)
+		job.size += len(line) + 1
+		if result.Error != "" {
+			job.failed++
+		}
+		job.mu.Unlock()
+	}
+	job.finish(batchDone)
+}
+
+// search satu target, pake algoritma yang sama kayak /api/search. Tiap target
+// dibatesin batchTargetTimeout biar satu target yang lambat gak nahan runner;
+// resep yang udah ketemu sebelum timeout tetap dikirim.
+func (job *batchJob) search(target string) batchResult {
+	result := batchResult{Target: target, TreeData: []*util.Node{}}
+	if !job.ds.Has(target) {
+		result.Error = fmt.Sprintf("unknown element %q", target)
+		return result
+	}
+
+	start := time.Now()
+	var trees []*util.Node
+	if job.ds.HasReactions() {
+		found := util.MultipleReactions(target, job.ds, job.policy, job.req.MaksimalResep)
+		trees, result.NodeVisited = util.BuildMultipleReactionTrees(target, found)
+		result.Stats = found.Stats
+	} else {
+		ctx, cancel := context.WithTimeout(job.ctx, batchTargetTimeout)
+		found := job.searcher.Search(target, job.ds, job.policy, util.SearchOptions{
+			MaxRecipes: job.req.MaksimalResep,
+			Workers:    searchWorkers,
+			Budget:     job.budget,
+			BeamWidth:  job.req.BeamWidth,
+			Context:    ctx,
+		})
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Error = fmt.Sprintf("search timed out after %v; results are partial", batchTargetTimeout)
+		}
+		cancel()
+		trees, result.NodeVisited = util.BuildMultipleTrees(target, found)
+		result.Stats = found.Stats
+	}
+	result.TimeTaken = time.Since(start).String()
+
+	if len(trees) > job.req.MaksimalResep {
+		trees = trees[:job.req.MaksimalResep]
+	}
+	if trees != nil {
+		result.TreeData, result.DAGData = job.req.shapeTrees(trees, job.ds, job.policy)
+	}
+	return result
+}
+
+// batchQueue antrian job batch dengan jumlah runner tetap
+type batchQueue struct {
+	mu      sync.Mutex
+	nextID  int
+	jobs    map[string]*batchJob
+	order   []string
+	pending chan *batchJob
+}
+
+var batches *batchQueue
+
+// newBatchQueue bikin antrian dan langsung jalanin runner-nya
+func newBatchQueue(runners, pending int) *batchQueue {
+	q := &batchQueue{
+		jobs:    make(map[string]*batchJob),
+		pending: make(chan *batchJob, pending),
+	}
+	for i := 0; i < runners; i++ {
+		go func() {
+			for job := range q.pending {
+				job.run()
+			}
+		}()
+	}
+	return q
+}
+
+// Submit masukin job ke antrian, error kalo antriannya penuh
+func (q *batchQueue) Submit(job *batchJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// ID di-set sebelum dikirim karena runner bisa langsung baca job.id, tapi
+	// nextID baru naik kalo job-nya beneran masuk antrian
+	job.id = fmt.Sprintf("b%d", q.nextID+1)
+	select {
+	case q.pending <- job:
+		q.nextID++
+	default:
+		job.id = ""
+		return fmt.Errorf("batch queue is full, try again later")
+	}
+
+	q.jobs[job.id] = job
+	q.order = append(q.order, job.id)
+	q.evict()
+	return nil
+}
+
+// evict buang job lama yang udah selesai kalo job yang disimpen kebanyakan
+func (q *batchQueue) evict() {
+	for i := 0; len(q.order) > maxBatchJobs && i < len(q.order); {
+		status := q.jobs[q.order[i]].Status().Status
+		if status != batchDone && status != batchCancelled && status != batchFailed {
+			i++
+			continue
+		}
+		delete(q.jobs, q.order[i])
+		q.order = append(q.order[:i], q.order[i+1:]...)
+	}
+}
+
+// Get nyari job berdasarkan ID
+func (q *batchQueue) Get(id string) (*batchJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, exists := q.jobs[id]
+	return job, exists
+}
+
+// newBatchJob ngecek request dan nyusun job-nya, kayak pengecekan di searchHandler
+func newBatchJob(req BatchRequest) (*batchJob, error) {
+	if req.RankBy != "" || req.Diverse {
+		return nil, fmt.Errorf("rankBy and diverse are not supported in batch")
+	}
+	if req.MaksimalResep <= 0 {
+		req.MaksimalResep = 1
+	}
+	if req.MaksimalResep > maxBatchRecipes {
+		return nil, fmt.Errorf("maksimalResep is at most %d in batch", maxBatchRecipes)
+	}
+	budget, err := req.memoryBudget()
+	if err != nil {
+		return nil, err
+	}
+	policy, err := util.PolicyByName(req.Policy)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.validateFormat(); err != nil {
+		return nil, err
+	}
+	ds, err := datasetByName(req.Dataset)
+	if err != nil {
+		return nil, err
+	}
+	var searcher util.Searcher
+	if ds.HasReactions() {
+		if err := req.validateReactions(); err != nil {
+			return nil, err
+		}
+	} else if searcher, err = util.SearcherByName(req.Algoritma); err != nil {
+		return nil, err
+	}
+
+	targets := req.Targets
+	if req.All {
+		targets = nil
+		for elem := range ds.TierMap {
+			if !ds.IsBase(elem) {
+				targets = append(targets, elem)
+			}
+		}
+		sort.Strings(targets)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("targets is empty")
+	}
+	if len(targets) > maxBatchTargets {
+		return nil, fmt.Errorf("too many targets: %d (max %d)", len(targets), maxBatchTargets)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &batchJob{
+		req:      req,
+		targets:  targets,
+		ds:       ds,
+		policy:   policy,
+		searcher: searcher,
+		budget:   budget,
+		ctx:      ctx,
+		cancel:   cancel,
+		status:   batchQueued,
+		created:  time.Now(),
+	}, nil
+}
+
+// batchHandler bikin job batch baru (POST /api/batch) dan langsung ngembaliin
+// ID-nya; hasilnya di-poll lewat /api/batch/{id}
+func batchHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("JSON decode error:", err)
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+		return
+	}
+
+	job, err := newBatchJob(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := batches.Submit(job); err != nil {
+		job.cancel()
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	log.Printf("Batch %s queued: %d targets, Algoritma=%s", job.id, len(job.targets), req.Algoritma)
+
+	// Content-Type harus di-set sebelum WriteHeader, writeJSON telat
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, job.Status())
+}
+
+// batchJobFromPath ngambil job dari {id} di path, jawab 404 kalo gak ada
+func batchJobFromPath(w http.ResponseWriter, r *http.Request) (*batchJob, bool) {
+	job, exists := batches.Get(r.PathValue("id"))
+	if !exists {
+		http.Error(w, "Batch job not found", http.StatusNotFound)
+	}
+	return job, exists
+}
+
+// batchStatusHandler progress job (GET /api/batch/{id})
+func batchStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	if job, ok := batchJobFromPath(w, r); ok {
+		writeJSON(w, job.Status())
+	}
+}
+
+// batchCancelHandler batalin job (POST /api/batch/{id}/cancel). Hasil yang
+// udah selesai tetap bisa di-download.
+func batchCancelHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	job, ok := batchJobFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	job.cancel()
+	// Job yang masih ngantri langsung dianggap batal; yang lagi jalan berhenti
+	// sendiri, pencarian target yang lagi jalan ikut dihentiin
+	job.mu.Lock()
+	queued := job.status == batchQueued
+	job.mu.Unlock()
+	if queued {
+		job.finish(batchCancelled)
+	}
+	writeJSON(w, job.Status())
+}
+
+// batchResultsHandler download hasil sebagai NDJSON, satu target per baris
+// (GET /api/batch/{id}/results). Job yang belum selesai ngasih hasil sejauh ini.
+func batchResultsHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	job, ok := batchJobFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	job.mu.Lock()
+	lines := job.results[:len(job.results):len(job.results)]
+	job.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "batch-"+job.id+".ndjson"))
+	for _, line := range lines {
+		w.Write(line)
+		w.Write([]byte("\n"))
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+)
+
+// File katalog hasil cmd/catalog, di-serve apa adanya tanpa dihitung ulang
+const (
+	catalogFile    = "data/catalog.json"
+	catalogCSVFile = "data/catalog.csv"
+)
+
+// catalogHandler ngasih katalog semua elemen (GET /api/catalog, ?format=csv buat CSV)
+func catalogHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	path, contentType := catalogFile, "application/json"
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+	case "csv":
+		path, contentType = catalogCSVFile, "text/csv; charset=utf-8"
+	default:
+		http.Error(w, "format must be json or csv", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, "Catalog has not been generated; run go run ./cmd/catalog", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	http.ServeFile(w, r, path)
+}
@@ -0,0 +1,117 @@
+// catalog ngitung ringkasan resep semua elemen (resep terpendek, jumlah resep,
+// kedalaman dan langkah minimal) terus nulis JSON dan CSV-nya. Server ngasih
+// file JSON-nya apa adanya lewat /api/catalog.
+//
+// Contoh:
+//
+//	go run ./cmd/catalog -file data/recipes.json -json data/catalog.json -csv data/catalog.csv
+//	go run ./cmd/catalog -policy acyclic -cap 500 -json data/catalog-acyclic.json -csv ""
+//
+// Langkah minimal bisa mahal buat elemen tier tinggi; -steps-budget ngebatesin
+// usahanya, elemen yang kehabisan budget ditandain minStepsExact: false. Budget
+// default sama kayak /api/analytics, jadi minSteps keduanya sama; budget lebih
+// gede bisa ngasih minSteps yang lebih kecil atau lebih banyak yang pasti.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"backend/scraper"
+	"backend/util"
+)
+
+func main() {
+	file := flag.String("file", "data/recipes.json", "path to recipes.json")
+	name := flag.String("name", "la2", "dataset name recorded in the catalog")
+	base := flag.String("base", "", "comma-separated base elements (default: Little Alchemy 2)")
+	policyName := flag.String("policy", "", "validity policy (default strict-tier)")
+	recipeCap := flag.Int("cap", util.DefaultCatalogRecipeCap, "maximum distinct recipes counted per element")
+	stepsBudget := flag.Int("steps-budget", util.DefaultCatalogStepsBudget, "partial recipes expanded per element when searching for minimum steps")
+	workers := flag.Int("workers", runtime.NumCPU(), "elements computed in parallel")
+	jsonOut := flag.String("json", "data/catalog.json", "JSON file to write (empty to skip)")
+	csvOut := flag.String("csv", "data/catalog.csv", "CSV file to write (empty to skip)")
+	flag.Parse()
+
+	policy, err := util.PolicyByName(*policyName)
+	if err != nil {
+		fatal(err)
+	}
+	baseElements := util.LittleAlchemy2BaseElements
+	if *base != "" {
+		baseElements = nil
+		for _, elem := range strings.Split(*base, ",") {
+			baseElements = append(baseElements, strings.TrimSpace(elem))
+		}
+	}
+
+	combinations, revCombinations, tierMap, err := scraper.UnmarshalRecipes(*file)
+	if err != nil {
+		fatal(err)
+	}
+	ds := util.NewDataset(*name, baseElements, combinations, revCombinations, tierMap)
+
+	start := time.Now()
+	catalog, err := util.BuildCatalog(ds, policy, util.CatalogOptions{
+		RecipeCap:   *recipeCap,
+		StepsBudget: *stepsBudget,
+		Workers:     *workers,
+	})
+	if err != nil {
+		fatal(err)
+	}
+
+	if *jsonOut != "" {
+		if err := writeFile(*jsonOut, catalog.WriteJSON); err != nil {
+			fatal(err)
+		}
+	}
+	if *csvOut != "" {
+		if err := writeFile(*csvOut, catalog.WriteCSV); err != nil {
+			fatal(err)
+		}
+	}
+
+	inexact, unreachable := 0, 0
+	for _, entry := range catalog.Elements {
+		if !entry.Reachable {
+			unreachable++
+		} else if !entry.MinStepsExact {
+			inexact++
+		}
+	}
+	fmt.Printf("Cataloged %d elements (policy %s) in %v: %d unreachable, %d with approximate minimum steps\n",
+		len(catalog.Elements), catalog.Policy, time.Since(start).Round(time.Millisecond), unreachable, inexact)
+}
+
+// writeFile nulis lewat file sementara biar server gak pernah ngasih file setengah jadi
+func writeFile(path string, write func(w io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "catalog:", err)
+	os.Exit(2)
+}
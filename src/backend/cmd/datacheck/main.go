@@ -0,0 +1,90 @@
+// datacheck ngecek integritas recipes.json hasil scrape.
+//
+// Contoh:
+//
+//	go run ./cmd/datacheck -file data/recipes.json -fail-on warning
+//	go run ./cmd/datacheck -file data/la1.json -base Air,Earth,Fire,Water
+//
+// Exit code 0 kalo gak ada temuan dengan severity >= -fail-on, 1 kalo ada,
+// 2 kalo file-nya gak bisa dibaca atau flag-nya salah.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"backend/datacheck"
+	"backend/util"
+)
+
+func main() {
+	file := flag.String("file", "data/recipes.json", "path to recipes.json")
+	failOn := flag.String("fail-on", "error", "lowest severity that makes the check fail (info, warning, error)")
+	minSeverity := flag.String("min-severity", "info", "lowest severity to print")
+	policyName := flag.String("policy", "", "validity policy used for reachability checks (default strict-tier)")
+	base := flag.String("base", "", "comma-separated base elements (default: Little Alchemy 2)")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	threshold, err := datacheck.ParseSeverity(*failOn)
+	if err != nil {
+		fatal(err)
+	}
+	shown, err := datacheck.ParseSeverity(*minSeverity)
+	if err != nil {
+		fatal(err)
+	}
+	policy, err := util.PolicyByName(*policyName)
+	if err != nil {
+		fatal(err)
+	}
+
+	var baseElements []string
+	if *base != "" {
+		for _, elem := range strings.Split(*base, ",") {
+			baseElements = append(baseElements, strings.TrimSpace(elem))
+		}
+	}
+
+	report, err := datacheck.CheckFile(*file, baseElements, policy)
+	if err != nil {
+		fatal(err)
+	}
+
+	// Temuan di bawah -min-severity cuma disembunyiin, tetap dihitung di Counts
+	visible := make([]datacheck.Finding, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		if finding.Severity >= shown {
+			visible = append(visible, finding)
+		}
+	}
+
+	if *asJSON {
+		out := report
+		out.Findings = visible
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			fatal(err)
+		}
+	} else {
+		for _, finding := range visible {
+			fmt.Println(finding)
+		}
+		fmt.Printf("%d elements, %d pairs, policy %s: %d errors, %d warnings, %d info\n",
+			report.Elements, report.Pairs, report.Policy,
+			report.Counts["error"], report.Counts["warning"], report.Counts["info"])
+	}
+
+	if report.Failed(threshold) {
+		os.Exit(1)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "datacheck:", err)
+	os.Exit(2)
+}
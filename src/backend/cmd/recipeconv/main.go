@@ -0,0 +1,85 @@
+// recipeconv nulis ulang file resep (v1 atau v2) ke format v2, atau bikin
+// snapshot biner buat startup cepet.
+//
+// Contoh:
+//
+//	go run ./cmd/recipeconv -in data/recipes.json -out data/recipes.v2.json
+//	go run ./cmd/recipeconv -in data/recipes.json -snapshot data/recipes.snap
+//
+// File v1 gak punya waktu scrape, jadi scrapedAt diambil dari modtime file-nya
+// kecuali -scraped-at diisi.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"backend/scraper"
+)
+
+func main() {
+	in := flag.String("in", "data/recipes.json", "recipe file to read (v1 or v2)")
+	out := flag.String("out", "", "file to write (default: overwrite -in)")
+	source := flag.String("source", scraper.ElementsURL, "source recorded in the header when converting v1 data")
+	scrapedAtFlag := flag.String("scraped-at", "", "scrape time (RFC 3339) recorded when converting v1 data")
+	snapshot := flag.String("snapshot", "", "write a binary snapshot to this file instead of converting")
+	flag.Parse()
+
+	if *snapshot != "" {
+		if err := scraper.WriteSnapshotFile(*in, *snapshot); err != nil {
+			fatal(err)
+		}
+		info, err := os.Stat(*snapshot)
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Printf("Wrote snapshot of %s to %s (%d bytes)\n", *in, *snapshot, info.Size())
+		return
+	}
+
+	if *out == "" {
+		*out = *in
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		fatal(err)
+	}
+	recipes, header, err := scraper.DecodeRecipeJSON(data)
+	if err != nil {
+		fatal(err)
+	}
+
+	// File v2 yang udah ada dipertahanin header-nya
+	src, scrapedAt := *source, time.Time{}
+	if header != nil {
+		src, scrapedAt = header.Source, header.ScrapedAt
+	}
+	if *scrapedAtFlag != "" {
+		scrapedAt, err = time.Parse(time.RFC3339, *scrapedAtFlag)
+		if err != nil {
+			fatal(err)
+		}
+	} else if scrapedAt.IsZero() {
+		info, err := os.Stat(*in)
+		if err != nil {
+			fatal(err)
+		}
+		scrapedAt = info.ModTime()
+	}
+
+	if err := scraper.WriteRecipesFile(*out, recipes, src, scrapedAt); err != nil {
+		fatal(err)
+	}
+
+	before := len(data)
+	after, _ := os.Stat(*out)
+	fmt.Printf("Wrote %d elements to %s (%d -> %d bytes)\n", len(recipes), *out, before, after.Size())
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "recipeconv:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,105 @@
+// rescrape scrape ulang wiki dan cuma nulis recipes.json kalo perubahannya wajar.
+//
+// Contoh:
+//
+//	go run ./cmd/rescrape -file data/recipes.json -report data/changes.json
+//	go run ./cmd/rescrape -dry-run -json
+//	go run ./cmd/rescrape -icons data/icons
+//
+// Exit code 0 kalo file ditulis atau gak ada perubahan, 1 kalo perubahan ditolak
+// threshold, 2 kalo ada error.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"backend/scraper"
+)
+
+func main() {
+	file := flag.String("file", "data/recipes.json", "recipe file to update")
+	reportFile := flag.String("report", "", "also write the change report as JSON to this file")
+	asJSON := flag.Bool("json", false, "print the change report as JSON instead of a summary")
+	dryRun := flag.Bool("dry-run", false, "only report changes, never write the recipe file")
+	force := flag.Bool("force", false, "write the recipe file even if the sanity threshold is exceeded")
+	iconDir := flag.String("icons", "", "also download element icons into this icon store (e.g. data/icons)")
+	maxRemovedElements := flag.Float64("max-removed-elements", scraper.DefaultSanityThreshold.MaxRemovedElements, "fraction of existing elements allowed to disappear")
+	maxRemovedCombinations := flag.Float64("max-removed-combinations", scraper.DefaultSanityThreshold.MaxRemovedCombinations, "fraction of existing combinations allowed to disappear")
+	maxTierChanges := flag.Float64("max-tier-changes", scraper.DefaultSanityThreshold.MaxTierChanges, "fraction of existing elements allowed to change tier")
+	flag.Parse()
+
+	report, err := scraper.IncrementalScrape(*file, scraper.IncrementalOptions{
+		Threshold: scraper.SanityThreshold{
+			MaxRemovedElements:     *maxRemovedElements,
+			MaxRemovedCombinations: *maxRemovedCombinations,
+			MaxTierChanges:         *maxTierChanges,
+		},
+		DryRun:  *dryRun,
+		Force:   *force,
+		IconDir: *iconDir,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "rescrape:", err)
+		os.Exit(2)
+	}
+
+	if *reportFile != "" {
+		if err := scraper.WriteChangeReport(*reportFile, report); err != nil {
+			fmt.Fprintln(os.Stderr, "rescrape:", err)
+			os.Exit(2)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printSummary(report)
+	}
+
+	if report.HasChanges() && !report.Written && !*dryRun {
+		os.Exit(1)
+	}
+}
+
+func printSummary(report scraper.ChangeReport) {
+	fmt.Printf("Elements: %d -> %d (+%d, -%d)\n", report.OldElements, report.NewElements, len(report.AddedElements), len(report.RemovedElements))
+	fmt.Printf("Combinations: %d -> %d (+%d, -%d)\n", report.OldCombinations, report.NewCombinations, len(report.AddedCombinations), len(report.RemovedCombinations))
+	fmt.Printf("Reactions: %d -> %d (+%d, -%d)\n", report.OldReactions, report.NewReactions, len(report.AddedReactions), len(report.RemovedReactions))
+	fmt.Printf("Tier changes: %d, asset changes: %d\n", len(report.TierChanges), report.AssetChanges)
+	if report.Icons != nil {
+		fmt.Printf("Icons: %d downloaded, %d unchanged, %d failed\n", report.Icons.Downloaded, report.Icons.Unchanged, report.Icons.Failed)
+	}
+
+	for _, name := range report.AddedElements {
+		fmt.Println("  + element", name)
+	}
+	for _, name := range report.RemovedElements {
+		fmt.Println("  - element", name)
+	}
+	for _, change := range report.TierChanges {
+		fmt.Printf("  ~ tier    %s: %d -> %d\n", change.Element, change.From, change.To)
+	}
+	for _, change := range report.AddedCombinations {
+		fmt.Printf("  + combo   %s = %s\n", change.Element, change.Ingredients)
+	}
+	for _, change := range report.RemovedCombinations {
+		fmt.Printf("  - combo   %s = %s\n", change.Element, change.Ingredients)
+	}
+	for _, change := range report.AddedReactions {
+		fmt.Printf("  + react   %s: %s\n", change.Element, change.Ingredients)
+	}
+	for _, change := range report.RemovedReactions {
+		fmt.Printf("  - react   %s: %s\n", change.Element, change.Ingredients)
+	}
+
+	status := "not written"
+	if report.Written {
+		status = "written"
+	}
+	fmt.Printf("Recipe file %s: %s\n", status, report.Reason)
+}
@@ -0,0 +1,338 @@
+package datacheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"backend/scraper"
+	"backend/util"
+)
+
+// Severity tingkat keparahan temuan
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity kebalikan dari String
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(name) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	}
+	return 0, fmt.Errorf("unknown severity %q (want info, warning or error)", name)
+}
+
+// Kode-kode temuan, biar gampang di-filter atau di-grep
+const (
+	CodeInvalidTier      = "invalid-tier"      // Tier negatif, biasanya getTierNumber gagal parse
+	CodeZeroTier         = "zero-tier"         // Elemen non-dasar yang tiernya 0
+	CodeDuplicateElement = "duplicate-element" // Result yang muncul lebih dari sekali
+	CodeDuplicatePair    = "duplicate-pair"    // Pair yang sama ditulis dua kali buat satu result
+	CodeAsymmetricPair   = "asymmetric-pair"   // A+B ada tapi B+A gak ada
+	CodeConflictingPair  = "conflicting-pair"  // Satu pair ngasilin lebih dari satu result (wajar di game, cuma info)
+	CodeOrphanReference  = "orphan-reference"  // Bahan yang gak ada di daftar elemen
+	CodeSelfReference    = "self-reference"    // Elemen yang butuh dirinya sendiri
+	CodeNoValidPairs     = "no-valid-pairs"    // Punya kombinasi tapi gak ada yang lolos policy
+	CodeNoCombinations   = "no-combinations"   // Elemen non-dasar tanpa kombinasi sama sekali
+	CodeUnreachable      = "unreachable"       // Gak bisa dibikin dari elemen dasar menurut policy
+)
+
+// Finding satu masalah yang ketemu di data
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Element  string   `json:"element"`
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%-7s %-17s %s: %s", f.Severity, f.Code, f.Element, f.Message)
+}
+
+// Report hasil pengecekan satu file resep
+type Report struct {
+	Policy   string         `json:"policy"`
+	Elements int            `json:"elements"`
+	Pairs    int            `json:"pairs"`
+	Findings []Finding      `json:"findings"`
+	Counts   map[string]int `json:"counts"` // Jumlah temuan per severity
+}
+
+// Max severity paling tinggi di report, -1 kalo gak ada temuan
+func (r Report) Max() Severity {
+	max := Severity(-1)
+	for _, finding := range r.Findings {
+		if finding.Severity > max {
+			max = finding.Severity
+		}
+	}
+	return max
+}
+
+// Failed true kalo ada temuan dengan severity >= threshold
+func (r Report) Failed(threshold Severity) bool {
+	return len(r.Findings) > 0 && r.Max() >= threshold
+}
+
+// CheckFile baca recipes.json lalu jalanin semua pengecekan
+func CheckFile(filename string, baseElements []string, policy util.ValidityPolicy) (Report, error) {
+	recipes, err := scraper.LoadRecipeJSON(filename)
+	if err != nil {
+		return Report{}, err
+	}
+	return Check(recipes, baseElements, policy), nil
+}
+
+// Check jalanin semua pengecekan integritas ke entri mentah recipes.json.
+// Reachability dihitung dari baseElements (nil berarti elemen dasar Little Alchemy 2)
+// pake policy yang dikasih (nil berarti util.DefaultPolicy).
+func Check(recipes []scraper.RecipeJSON, baseElements []string, policy util.ValidityPolicy) Report {
+	if policy == nil {
+		policy = util.DefaultPolicy
+	}
+	if baseElements == nil {
+		baseElements = util.LittleAlchemy2BaseElements
+	}
+
+	c := &checker{
+		base:   baseElements,
+		isBase: make(map[string]bool),
+		known:  make(map[string]bool),
+	}
+	for _, base := range baseElements {
+		c.isBase[base] = true
+	}
+	for _, recipe := range recipes {
+		c.known[recipe.Result] = true
+	}
+
+	c.checkElements(recipes)
+	c.checkPairs(recipes)
+	pairs := c.checkReachability(recipes, policy)
+
+	sort.SliceStable(c.findings, func(i, j int) bool {
+		a, b := c.findings[i], c.findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return a.Element < b.Element
+	})
+
+	report := Report{
+		Policy:   policy.Name(),
+		Elements: len(c.known),
+		Pairs:    pairs,
+		Findings: c.findings,
+		Counts:   make(map[string]int),
+	}
+	if report.Findings == nil {
+		report.Findings = []Finding{}
+	}
+	for _, finding := range report.Findings {
+		report.Counts[finding.Severity.String()]++
+	}
+	return report
+}
+
+type checker struct {
+	base     []string
+	isBase   map[string]bool
+	known    map[string]bool
+	findings []Finding
+}
+
+func (c *checker) add(severity Severity, code, element, format string, args ...any) {
+	c.findings = append(c.findings, Finding{
+		Severity: severity,
+		Code:     code,
+		Element:  element,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// checkElements tier dan duplikasi per elemen
+func (c *checker) checkElements(recipes []scraper.RecipeJSON) {
+	seen := make(map[string]int)
+	for _, recipe := range recipes {
+		seen[recipe.Result]++
+		if seen[recipe.Result] == 2 {
+			c.add(SeverityError, CodeDuplicateElement, recipe.Result, "element is listed more than once; later entries overwrite earlier tiers")
+		}
+
+		switch {
+		case recipe.Tier < 0:
+			c.add(SeverityError, CodeInvalidTier, recipe.Result, "tier %d (tier heading could not be parsed)", recipe.Tier)
+		case !c.isBase[recipe.Result] && recipe.Tier == 0:
+			c.add(SeverityWarning, CodeZeroTier, recipe.Result, "non-base element has tier 0")
+		}
+	}
+
+	for _, base := range c.base {
+		if !c.known[base] {
+			c.add(SeverityError, CodeOrphanReference, base, "base element is missing from the element list")
+		}
+	}
+}
+
+// checkPairs duplikat, asimetri, konflik, dan referensi ke elemen yang gak ada
+func (c *checker) checkPairs(recipes []scraper.RecipeJSON) {
+	producers := make(map[util.Pair][]string)
+	orphans := make(map[string]map[string]bool)
+
+	for _, recipe := range recipes {
+		ordered := make(map[util.Pair]bool)
+		selfReported := make(map[util.Pair]bool)
+		for _, pair := range recipe.Combinations {
+			if ordered[pair] {
+				c.add(SeverityWarning, CodeDuplicatePair, recipe.Result, "pair %s+%s is listed more than once", pair.First, pair.Second)
+				continue
+			}
+			ordered[pair] = true
+
+			if (pair.First == recipe.Result || pair.Second == recipe.Result) && !selfReported[normalizedPair(pair)] {
+				selfReported[normalizedPair(pair)] = true
+				c.add(SeverityWarning, CodeSelfReference, recipe.Result, "pair %s+%s uses the element itself", pair.First, pair.Second)
+			}
+			for _, ingredient := range []string{pair.First, pair.Second} {
+				if !c.known[ingredient] {
+					if orphans[recipe.Result] == nil {
+						orphans[recipe.Result] = make(map[string]bool)
+					}
+					orphans[recipe.Result][ingredient] = true
+				}
+			}
+		}
+
+		for pair := range ordered {
+			if pair.First == pair.Second {
+				continue
+			}
+			// Kalo mirror-nya gak ada, pair ini cuma muncul sekali jadi cukup dilaporin di sini
+			if !ordered[util.Pair{First: pair.Second, Second: pair.First}] {
+				c.add(SeverityWarning, CodeAsymmetricPair, recipe.Result, "pair %s+%s has no mirrored %s+%s entry", pair.First, pair.Second, pair.Second, pair.First)
+			}
+		}
+		for pair := range ordered {
+			key := normalizedPair(pair)
+			if !containsString(producers[key], recipe.Result) {
+				producers[key] = append(producers[key], recipe.Result)
+			}
+		}
+	}
+
+	for result, ingredients := range orphans {
+		names := make([]string, 0, len(ingredients))
+		for name := range ingredients {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		c.add(SeverityError, CodeOrphanReference, result, "combinations reference unknown elements: %s", strings.Join(names, ", "))
+	}
+
+	for pair, results := range producers {
+		if len(results) > 1 {
+			sort.Strings(results)
+			for _, result := range results {
+				c.add(SeverityInfo, CodeConflictingPair, result, "pair %s+%s also produces %s", pair.First, pair.Second, strings.Join(without(results, result), ", "))
+			}
+		}
+	}
+}
+
+// checkReachability elemen yang gak bisa dibikin dari elemen dasar menurut policy.
+// Ngembaliin jumlah pair unik (tak berurut) di data.
+func (c *checker) checkReachability(recipes []scraper.RecipeJSON, policy util.ValidityPolicy) int {
+	revCombinations := make(map[string][]util.Pair)
+	tierMap := make(map[string]int)
+	unique := make(map[util.Pair]bool)
+	for _, recipe := range recipes {
+		tierMap[recipe.Result] = recipe.Tier
+		revCombinations[recipe.Result] = append(revCombinations[recipe.Result], recipe.Combinations...)
+		for _, pair := range recipe.Combinations {
+			unique[normalizedPair(pair)] = true
+		}
+	}
+
+	levels := util.DerivationLevels(util.NewDataset("", c.base, nil, revCombinations, tierMap), policy)
+
+	for elem := range c.known {
+		if c.isBase[elem] {
+			continue
+		}
+		pairs := revCombinations[elem]
+		if len(pairs) == 0 {
+			c.add(SeverityWarning, CodeNoCombinations, elem, "non-base element has no combinations")
+			continue
+		}
+
+		allowed := 0
+		for _, pair := range pairs {
+			if policy.Allows(elem, pair, tierMap) {
+				allowed++
+			}
+		}
+		if allowed == 0 {
+			c.add(SeverityWarning, CodeNoValidPairs, elem, "none of its %d combinations are allowed by the %s policy", len(pairs), policy.Name())
+			continue
+		}
+		if _, reachable := levels[elem]; !reachable {
+			c.add(SeverityWarning, CodeUnreachable, elem, "cannot be built from base elements under the %s policy", policy.Name())
+		}
+	}
+
+	return len(unique)
+}
+
+func normalizedPair(pair util.Pair) util.Pair {
+	if pair.Second < pair.First {
+		return util.Pair{First: pair.Second, Second: pair.First}
+	}
+	return pair
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func without(values []string, value string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
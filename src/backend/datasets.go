@@ -0,0 +1,245 @@
+package main
+
+import (
+	"backend/scraper"
+	"backend/util"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// defaultDatasetName dataset bawaan: Little Alchemy 2 dari wiki fandom
+const defaultDatasetName = "la2"
+
+// Cache snapshot runtime ada di data/cache (di-gitignore), jangan sampe nimpa
+// src/backend/data/recipes.snap yang di-embed kalo server dijalanin dari src/backend
+const (
+	recipeFile    = "data/recipes.json"
+	snapshotFile  = "data/cache/recipes.snap"
+	sourcesFile   = "data/sources.json"
+	overridesFile = "data/overrides.json"
+	datasetsFile  = "data/datasets.json"
+	datasetsDir   = "data/cache/datasets" // Snapshot dataset tambahan
+)
+
+// DatasetConfig satu dataset tambahan di datasets.json. Sumbernya sama kayak
+// sources.json, elemen dasarnya wajib diisi karena tiap game beda-beda:
+//
+//	{
+//	  "default": "la2",
+//	  "datasets": [
+//	    {
+//	      "name": "la1",
+//	      "baseElements": ["Air", "Earth", "Fire", "Water"],
+//	      "sources": [{"type": "file", "path": "data/la1.csv"}]
+//	    }
+//	  ]
+//	}
+type DatasetConfig struct {
+	Name         string                 `json:"name"`
+	BaseElements []string               `json:"baseElements"`
+	Sources      []scraper.SourceConfig `json:"sources"`
+}
+
+type datasetsConfig struct {
+	Default  string          `json:"default,omitempty"`
+	Datasets []DatasetConfig `json:"datasets"`
+}
+
+// datasetNamePattern nama dataset juga dipake buat nama file snapshot-nya
+var datasetNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Dataset yang udah di-load, diisi sekali pas server start
+var (
+	datasets       = make(map[string]*util.Dataset)
+	defaultDataset = defaultDatasetName
+)
+
+// loadDatasets load dataset bawaan (la2) dan dataset tambahan dari datasets.json.
+// Dataset bawaan wajib ada; dataset tambahan yang gagal di-load cuma di-skip.
+func loadDatasets() {
+	recipes, err := loadRecipes(defaultSources(), snapshotFile)
+	if err != nil {
+		log.Fatalf("Loading recipe data failed: %v", err)
+	}
+	datasets[defaultDatasetName] = scraper.BuildDataset(defaultDatasetName, util.LittleAlchemy2BaseElements, recipes)
+
+	data, err := os.ReadFile(datasetsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", datasetsFile, err)
+	}
+	var config datasetsConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		log.Fatalf("Error parsing %s: %v", datasetsFile, err)
+	}
+
+	for _, dc := range config.Datasets {
+		if err := loadConfiguredDataset(dc); err != nil {
+			log.Printf("Skipping dataset %q: %v", dc.Name, err)
+		}
+	}
+
+	if config.Default != "" {
+		if _, exists := datasets[config.Default]; !exists {
+			log.Fatalf("Default dataset %q is not loaded", config.Default)
+		}
+		defaultDataset = config.Default
+	}
+}
+
+// loadConfiguredDataset load satu dataset dari datasets.json
+func loadConfiguredDataset(dc DatasetConfig) error {
+	if !datasetNamePattern.MatchString(dc.Name) {
+		return fmt.Errorf("invalid dataset name")
+	}
+	if _, exists := datasets[dc.Name]; exists {
+		return fmt.Errorf("dataset is defined more than once")
+	}
+	if len(dc.BaseElements) == 0 {
+		return fmt.Errorf("baseElements is required")
+	}
+
+	sources, err := scraper.ParseSources(dc.Sources)
+	if err != nil {
+		return err
+	}
+	recipes, err := loadRecipes(sources, filepath.Join(datasetsDir, dc.Name+".snap"))
+	if err != nil {
+		return err
+	}
+
+	ds := scraper.BuildDataset(dc.Name, dc.BaseElements, recipes)
+	for _, base := range dc.BaseElements {
+		if !ds.Has(base) {
+			log.Printf("Dataset %q: base element %q does not appear in its recipes", dc.Name, base)
+		}
+	}
+	datasets[dc.Name] = ds
+	log.Printf("Dataset %q loaded: %d elements, %d reactions.", dc.Name, len(ds.TierMap), len(ds.Reactions))
+	return nil
+}
+
+// defaultSources urutan sumber dataset bawaan dari sources.json; kalo gak ada pake
+// recipes.json (atau snapshot yang di-embed ke binary, atau scrape) ditambah overrides
+func defaultSources() []scraper.RecipeSource {
+	sources := scraper.DefaultSources(recipeFile, overridesFile, embeddedSnapshot)
+	if _, err := os.Stat(sourcesFile); err == nil {
+		configured, err := scraper.LoadSourceConfig(sourcesFile)
+		if err != nil {
+			log.Fatalf("Error loading recipe sources: %v", err)
+		}
+		sources = configured
+	}
+	return sources
+}
+
+// loadRecipes gabungin sources jadi daftar resep buat scraper.BuildDataset.
+// Hasilnya di-cache ke snapshotFile selama isi sumbernya gak berubah.
+func loadRecipes(sources []scraper.RecipeSource, snapshotFile string) ([]scraper.RecipeJSON, error) {
+	hookFandomSources(sources)
+
+	// Snapshot biner paling cepet, tapi cuma dipake kalo dibikin dari isi sumber yang sekarang
+	start := time.Now()
+	fingerprint, cacheable := scraper.SourcesFingerprint(sources)
+	if cacheable {
+		recipes, err := scraper.LoadSnapshotMatching(snapshotFile, fingerprint)
+		if err == nil {
+			log.Printf("Recipe data loaded from %s in %v.", snapshotFile, time.Since(start))
+			return recipes, nil
+		}
+		if !os.IsNotExist(err) {
+			log.Printf("Ignoring recipe snapshot: %v", err)
+		}
+	}
+
+	// Ensure data directory exists
+	os.MkdirAll(filepath.Dir(snapshotFile), os.ModePerm)
+
+	log.Printf("Loading recipe data from %d sources...", len(sources))
+	recipes, err := scraper.MergeSources(context.Background(), sources)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Recipe data loaded: %d elements.", len(recipes))
+
+	// Fingerprint dihitung ulang, sumber yang baru di-scrape udah nulis file-nya
+	if fingerprint, cacheable = scraper.SourcesFingerprint(sources); cacheable {
+		if err := scraper.SaveSnapshot(snapshotFile, recipes, fingerprint); err != nil {
+			log.Printf("Could not write recipe snapshot: %v", err)
+		}
+	}
+
+	return recipes, nil
+}
+
+// hookFandomSources download ikon di background tiap kali wiki di-scrape, server gak perlu nunggu
+func hookFandomSources(sources []scraper.RecipeSource) {
+	for _, source := range sources {
+		switch source := source.(type) {
+		case *scraper.FandomSource:
+			source.AfterScrape = func(recipes []scraper.RecipeJSON) { go downloadIcons(recipes) }
+		case scraper.FirstAvailable:
+			hookFandomSources(source)
+		}
+	}
+}
+
+// datasetByName dataset yang dipilih request, nama kosong berarti dataset default
+func datasetByName(name string) (*util.Dataset, error) {
+	if name == "" {
+		name = defaultDataset
+	}
+	ds, exists := datasets[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown dataset %q", name)
+	}
+	return ds, nil
+}
+
+// DatasetInfo ringkasan satu dataset di /api/datasets
+type DatasetInfo struct {
+	Name         string   `json:"name"`
+	BaseElements []string `json:"baseElements"`
+	Elements     int      `json:"elements"`
+	Combinations int      `json:"combinations"`        // Pair dihitung dua kali (A+B dan B+A)
+	Reactions    int      `json:"reactions,omitempty"` // Kalo ada, cuma algoritma "Reactions" yang bisa dipake
+}
+
+// datasetsHandler ngasih daftar dataset yang bisa dipilih lewat field dataset
+func datasetsHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	infos := make([]DatasetInfo, 0, len(datasets))
+	for _, ds := range datasets {
+		infos = append(infos, DatasetInfo{
+			Name:         ds.Name,
+			BaseElements: ds.BaseElements,
+			Elements:     len(ds.TierMap),
+			Combinations: len(ds.Combinations),
+			Reactions:    len(ds.Reactions),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	writeJSON(w, struct {
+		Default  string        `json:"default"`
+		Datasets []DatasetInfo `json:"datasets"`
+	}{
+		Default:  defaultDataset,
+		Datasets: infos,
+	})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"backend/util"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// DiffRequest dua resep yang mau dibandingin: langsung sebagai map resep,
+// atau sebagai index treeData dari pencarian sebelumnya (searchId)
+type DiffRequest struct {
+	Target   string                  `json:"target"`
+	RecipeA  map[string]util.Element `json:"recipeA,omitempty"`
+	RecipeB  map[string]util.Element `json:"recipeB,omitempty"`
+	SearchID string                  `json:"searchId,omitempty"`
+	IndexA   int                     `json:"indexA"`
+	IndexB   int                     `json:"indexB"`
+}
+
+// resolve ngisi target dan kedua resep dari cache kalo pake searchId
+func (req *DiffRequest) resolve() error {
+	if req.SearchID == "" {
+		if req.Target == "" || req.RecipeA == nil || req.RecipeB == nil {
+			return fmt.Errorf("target, recipeA and recipeB are required without searchId")
+		}
+		return nil
+	}
+
+	search, exists := recentSearches.Get(req.SearchID)
+	if !exists {
+		return fmt.Errorf("unknown or expired searchId %q", req.SearchID)
+	}
+	for _, idx := range []int{req.IndexA, req.IndexB} {
+		if idx < 0 || idx >= len(search.Recipes) {
+			return fmt.Errorf("index %d out of range, search has %d recipes", idx, len(search.Recipes))
+		}
+	}
+	if req.Target != "" && req.Target != search.Target {
+		return fmt.Errorf("search %q is for %q, not %q", req.SearchID, search.Target, req.Target)
+	}
+
+	req.Target = search.Target
+	req.RecipeA = search.Recipes[req.IndexA]
+	req.RecipeB = search.Recipes[req.IndexB]
+	return nil
+}
+
+func diffHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req DiffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Println("JSON decode error:", err)
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.resolve(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, util.DiffRecipes(req.RecipeA, req.RecipeB, req.Target))
+}
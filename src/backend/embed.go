@@ -0,0 +1,15 @@
+//go:build embedsnapshot
+
+package main
+
+import _ "embed"
+
+// embeddedSnapshot snapshot resep yang ikut di-compile ke binary. File-nya gak
+// di-commit; Dockerfile bikin dari data/recipes.json, kalo manual (dari src/backend):
+//
+//	go run ./cmd/recipeconv -in ../../data/recipes.json -snapshot data/recipes.snap
+//
+// lalu build pake -tags embedsnapshot.
+//
+//go:embed data/recipes.snap
+var embeddedSnapshot []byte
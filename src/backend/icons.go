@@ -0,0 +1,85 @@
+package main
+
+import (
+	"backend/scraper"
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+const iconDir = "data/icons"
+
+var (
+	iconStoreOnce sync.Once
+	iconStore     *scraper.IconStore
+)
+
+// loadIconStore buka icon store sekali. Kalo index-nya rusak, store-nya mulai dari kosong.
+func loadIconStore() *scraper.IconStore {
+	iconStoreOnce.Do(func() {
+		store, err := scraper.OpenIconStore(iconDir)
+		if err != nil {
+			log.Printf("Error loading icon store: %v", err)
+			store = scraper.NewIconStore(iconDir)
+		}
+		iconStore = store
+		log.Printf("Icon store loaded with %d icons.", store.Len())
+	})
+	return iconStore
+}
+
+// iconHandler ngelayanin ikon elemen dari icon store (GET /api/icons/{element}),
+// jadi UI gak perlu hotlink ke wikia. ETag-nya hash isi ikon.
+func iconHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	element := r.PathValue("element")
+	entry, path, exists := loadIconStore().Lookup(element)
+	if !exists {
+		http.Error(w, "Icon not found", http.StatusNotFound)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("Error opening icon for %s: %v", element, err)
+		http.Error(w, "Icon not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, "Failed to read icon", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", entry.ContentType)
+	w.Header().Set("ETag", `"`+entry.Hash+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	// SVG dari luar gak boleh jalanin script kalo dibuka langsung
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	http.ServeContent(w, r, "", info.ModTime(), file)
+}
+
+// iconURL alamat ikon element di /api/icons, kosong kalo ikonnya belum ada di store
+func iconURL(element string) string {
+	if _, _, exists := loadIconStore().Lookup(element); !exists {
+		return ""
+	}
+	return "/api/icons/" + url.PathEscape(element)
+}
+
+// downloadIcons tahap ikon pipeline scraper, dipanggil abis scrape ulang
+func downloadIcons(recipes []scraper.RecipeJSON) {
+	stats, err := scraper.DownloadIcons(context.Background(), scraper.FetchClient, recipes, loadIconStore())
+	log.Printf("Icons: %d downloaded, %d unchanged, %d failed.", stats.Downloaded, stats.Unchanged, stats.Failed)
+	if err != nil {
+		log.Printf("Some icon downloads failed: %v", err)
+	}
+}
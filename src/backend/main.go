@@ -1,101 +1,151 @@
 package main
 
 import (
-	"backend/scraper"
 	"backend/util"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"time"
 )
 
-// Global variables to store recipe data
-var (
-	globalRawRecipe         map[util.Pair]string
-	globalReversedRawRecipe map[string][]util.Pair
-	globalIngredientsTier   map[string]int
-	dataLoaded              bool = false
-)
+// searchWorkers jumlah worker algoritma Multiple*
+const searchWorkers = 4
 
-type SearchRequest struct {
-	NamaResep     string `json:"namaResep"`
-	MaksimalResep int    `json:"maksimalResep"`
-	Algoritma     string `json:"algoritma"`
-	ModePencarian string `json:"modePencarian"`
+// defaultMemoryBudget batas frontier default buat pencarian Multiple*,
+// bisa dioverride per request lewat maxFrontier dan overflow
+var defaultMemoryBudget = util.MemoryBudget{
+	MaxFrontier: 20000,
+	Overflow:    util.SpillOverflow,
 }
 
-// Updated TreeResponse to use the existing util.Node type directly
-type TreeResponse struct {
-	TreeData    []*util.Node `json:"treeData"`
-	TimeTaken   string       `json:"timetaken"`
-	NodeVisited int          `json:"node_visited"`
+type SearchRequest struct {
+	NamaResep     string  `json:"namaResep"`
+	MaksimalResep int     `json:"maksimalResep"`
+	Algoritma     string  `json:"algoritma"`
+	ModePencarian string  `json:"modePencarian"`
+	MaxFrontier   int     `json:"maxFrontier,omitempty"` // Override batas frontier di RAM
+	Overflow      string  `json:"overflow,omitempty"`    // "spill" atau "prune"
+	RankBy        string  `json:"rankBy,omitempty"`      // Mode k-best: "steps", "depth", atau "leaves"
+	Diverse       bool    `json:"diverse,omitempty"`     // Pilih resep yang strukturnya saling beda
+	MinDistance   float64 `json:"minDistance,omitempty"` // Jarak Jaccard minimal antar resep di mode diverse
+	Policy        string  `json:"policy,omitempty"`      // Nama ValidityPolicy, default strict-tier
+	Dataset       string  `json:"dataset,omitempty"`     // Nama dataset, default la2 (lihat /api/datasets)
+	Format        string  `json:"format,omitempty"`      // "tree" (default) atau "dag"
+	Metadata      bool    `json:"metadata,omitempty"`    // Isi meta tiap node di treeData (tier, ikon, langkah, dll)
+	BeamWidth     int     `json:"beamWidth,omitempty"`   // Lebar beam buat algoritma Beam, default util.DefaultBeamWidth
 }
 
-// loadRecipeData loads recipe data either from file or by scraping
-func loadRecipeData() (map[util.Pair]string, map[string][]util.Pair, map[string]int) {
-	// If data is already loaded, return the global variables
-	if dataLoaded {
-		return globalRawRecipe, globalReversedRawRecipe, globalIngredientsTier
-	}
-
-	const recipeFile = "data/recipes.json"
+// Format respons search
+const (
+	formatTree = "tree"
+	formatDAG  = "dag" // Node sekali per elemen + edge, lihat util.RecipeDAG
+)
 
-	// Check if file exists
-	_, err := os.Stat(recipeFile)
-	if err == nil {
-		// File exists, try to load it
-		log.Println("Loading recipe data from file...")
-		rawRecipe, reversedRawRecipe, ingredientsTier, err := scraper.UnmarshalRecipes(recipeFile)
-		if err == nil {
-			// Successfully loaded data from file
-			log.Println("Recipe data loaded from file successfully.")
+// validateFormat ngecek field format
+func (req SearchRequest) validateFormat() error {
+	switch req.Format {
+	case "", formatTree, formatDAG:
+		return nil
+	}
+	return fmt.Errorf("unsupported format %q", req.Format)
+}
 
-			// Store the loaded data in global variables
-			globalRawRecipe = rawRecipe
-			globalReversedRawRecipe = reversedRawRecipe
-			globalIngredientsTier = ingredientsTier
-			dataLoaded = true
+// writeTrees ngirim respons search sesuai format yang diminta
+func (req SearchRequest) writeTrees(w http.ResponseWriter, ds *util.Dataset, policy util.ValidityPolicy, response TreeResponse) {
+	response.TreeData, response.DAGData = req.shapeTrees(response.TreeData, ds, policy)
+	writeJSON(w, response)
+}
 
-			return rawRecipe, reversedRawRecipe, ingredientsTier
-		}
-		log.Printf("Error loading recipe data from file: %v. Falling back to scraping.", err)
+// shapeTrees ngubah tree hasil pencarian sesuai format. Di format dag, treeData
+// dikosongin dan tree-nya dikirim sebagai dagData (yang udah punya metadata
+// sendiri); di format tree, meta node cuma diisi kalo diminta.
+func (req SearchRequest) shapeTrees(trees []*util.Node, ds *util.Dataset, policy util.ValidityPolicy) ([]*util.Node, []util.RecipeDAG) {
+	if req.Format == formatDAG {
+		return []*util.Node{}, util.BuildDAGs(trees, ds.TierMap, iconURL)
+	}
+	if req.Metadata {
+		util.AnnotateTrees(trees, ds, policy, iconURL)
 	}
+	return trees, nil
+}
 
-	// File doesn't exist or couldn't be loaded, scrape the data
-	log.Println("Scraping recipe data...")
-	rawRecipe := make(map[util.Pair]string)
-	reversedRawRecipe := make(map[string][]util.Pair)
-	ingredientsTier := make(map[string]int)
+// Mode diverse milih dari kandidat yang lebih banyak dari jumlah resep yang diminta
+const (
+	diversityPoolFactor = 5
+	diversityMinPool    = 50
+)
 
-	// Ensure data directory exists
-	os.MkdirAll("data", os.ModePerm)
+// searchLimit jumlah resep yang dicari algoritma sebelum diseleksi
+func (req SearchRequest) searchLimit() int {
+	if !req.Diverse {
+		return req.MaksimalResep
+	}
+	return max(req.MaksimalResep*diversityPoolFactor, diversityMinPool)
+}
 
-	// Scrape and save to file
-	scraper.Scraper(rawRecipe, ingredientsTier, reversedRawRecipe, true)
+// selectDiverse ngembaliin index resep yang dipilih mode diverse beserta jarak minimalnya
+func (req SearchRequest) selectDiverse(recipes []map[string]util.Element) ([]int, float64) {
+	minDistance := req.MinDistance
+	if minDistance <= 0 {
+		minDistance = util.DefaultMinDistance
+	}
+	return util.SelectDiverse(recipes, req.NamaResep, req.MaksimalResep, minDistance)
+}
 
-	// Store the scraped data in global variables
-	globalRawRecipe = rawRecipe
-	globalReversedRawRecipe = reversedRawRecipe
-	globalIngredientsTier = ingredientsTier
-	dataLoaded = true
+// memoryBudget nyusun MemoryBudget dari default server dan override di request
+func (req SearchRequest) memoryBudget() (util.MemoryBudget, error) {
+	budget := defaultMemoryBudget
+	if req.MaxFrontier > 0 {
+		budget.MaxFrontier = req.MaxFrontier
+	}
+	switch util.OverflowPolicy(req.Overflow) {
+	case "":
+	case util.SpillOverflow, util.PruneOverflow:
+		budget.Overflow = util.OverflowPolicy(req.Overflow)
+	default:
+		return budget, fmt.Errorf("unsupported overflow policy %q", req.Overflow)
+	}
+	return budget, nil
+}
 
-	return rawRecipe, reversedRawRecipe, ingredientsTier
+// Updated TreeResponse to use the existing util.Node type directly
+type TreeResponse struct {
+	TreeData    []*util.Node      `json:"treeData"`
+	TimeTaken   string            `json:"timetaken"`
+	NodeVisited int               `json:"node_visited"`
+	Metric      string            `json:"metric,omitempty"`
+	Scores      []int             `json:"scores,omitempty"`  // Skor tiap tree di mode k-best
+	Spread      float64           `json:"spread,omitempty"`  // Jarak Jaccard minimal antar tree di mode diverse
+	SearchID    string            `json:"searchId"`          // Dipake /api/diff buat nunjuk tree di hasil ini
+	DAGData     []util.RecipeDAG  `json:"dagData,omitempty"` // Diisi kalo format dag
+	Stats       util.SearchStats  `json:"stats"`             // Statistik eksplorasi algoritma
+	Trace       *util.SearchTrace `json:"trace,omitempty"`   // Log langkah pencarian kalo ?trace=1
 }
 
-func searchHandler(w http.ResponseWriter, r *http.Request) {
+// allowMethod masang CORS header dan ngecek method request.
+// Ngembaliin false kalo request udah dijawab (preflight atau method salah).
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
 	// CORS headers
 	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Methods", method+", OPTIONS")
 
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
-		return
+		return false
 	}
 
-	if r.Method != http.MethodPost {
-		http.Error(w, "Only POST allowed", http.StatusMethodNotAllowed)
+	if r.Method != method {
+		http.Error(w, "Only "+method+" allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
 		return
 	}
 
@@ -109,26 +159,83 @@ func searchHandler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Received search: NamaResep=%s, MaksimalResep=%d, Algoritma=%s, ModePencarian=%s",
 		req.NamaResep, req.MaksimalResep, req.Algoritma, req.ModePencarian)
 
-	// Load recipe data (will only scrape if necessary)
-	rawRecipe, reversedRawRecipe, ingredientsTier := loadRecipeData()
+	budget, err := req.memoryBudget()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var result util.MultipleRecipesResult
-	start := time.Now()
+	policy, err := util.PolicyByName(req.Policy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	switch req.Algoritma {
-	case "BFS":
-		result = util.MultipleBfs(req.NamaResep, rawRecipe, reversedRawRecipe, ingredientsTier, req.MaksimalResep, 4)
-	case "DFS":
-		result = util.MultipleDfs(req.NamaResep, reversedRawRecipe, ingredientsTier, req.MaksimalResep, 4)
-	case "Bi-BFS":
-		result = util.MultipleBidirectional(req.NamaResep, rawRecipe, reversedRawRecipe, ingredientsTier, req.MaksimalResep, 4)
-	default:
-		http.Error(w, "Unsupported algorithm", http.StatusBadRequest)
+	if err := req.validateFormat(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ds, err := datasetByName(req.Dataset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// ?trace=1 atau ?trace=jsonl: satu resep terpendek beserta log langkah pencariannya
+	mode, limit, err := traceMode(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if mode != "" {
+		tracedSearch(w, req, ds, policy, mode, limit)
+		return
+	}
+
+	// Dataset dengan reaction N bahan / multi-produk gak bisa pake algoritma berbasis Pair
+	if ds.HasReactions() {
+		reactionSearch(w, req, ds, policy)
 		return
 	}
 
+	// Mode k-best: resep diurutkan berdasarkan metric, bukan urutan penemuan
+	if req.RankBy != "" {
+		rankedSearch(w, req, ds, policy)
+		return
+	}
+
+	searcher, err := util.SearcherByName(req.Algoritma)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	result := searcher.Search(req.NamaResep, ds, policy, util.SearchOptions{
+		MaxRecipes: req.searchLimit(),
+		Workers:    searchWorkers,
+		Budget:     budget,
+		BeamWidth:  req.BeamWidth,
+	})
 	elapsed := time.Since(start)
 
+	if result.Frontier.Pruned > 0 {
+		log.Printf("Frontier pruned %d items (peak %d, spilled %d)",
+			result.Frontier.Pruned, result.Frontier.Peak, result.Frontier.Spilled)
+	}
+
+	var spread float64
+	if req.Diverse {
+		var selected []int
+		selected, spread = req.selectDiverse(result.Recipes)
+		recipes := make([]map[string]util.Element, 0, len(selected))
+		for _, idx := range selected {
+			recipes = append(recipes, result.Recipes[idx])
+		}
+		result.Recipes = recipes
+	}
+
 	trees, nodeVisited := util.BuildMultipleTrees(req.NamaResep, result)
 
 	// Limit the number of trees to the max requested
@@ -136,7 +243,19 @@ func searchHandler(w http.ResponseWriter, r *http.Request) {
 		trees = trees[:req.MaksimalResep]
 	}
 
-	jsonData, err := util.ConvertToJSON(trees, nodeVisited, elapsed)
+	req.writeTrees(w, ds, policy, TreeResponse{
+		TreeData:    trees,
+		TimeTaken:   elapsed.String(),
+		NodeVisited: nodeVisited,
+		Spread:      spread,
+		SearchID:    recentSearches.Store(req.NamaResep, result.Recipes[:len(trees)]),
+		Stats:       result.Stats,
+	})
+}
+
+// writeJSON nulis respons apa aja sebagai JSON
+func writeJSON(w http.ResponseWriter, response any) {
+	jsonData, err := json.MarshalIndent(response, "", "  ")
 	if err != nil {
 		log.Printf("Error converting to JSON: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -148,14 +267,147 @@ func searchHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonData)
 }
 
+// rankedSearch ngejalanin KBestRecipes dan ngirim tree beserta skornya
+func rankedSearch(w http.ResponseWriter, req SearchRequest, ds *util.Dataset, policy util.ValidityPolicy) {
+	metric, err := util.ParseRecipeMetric(req.RankBy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	result := util.KBestRecipes(req.NamaResep, ds, policy, req.searchLimit(), metric)
+	elapsed := time.Since(start)
+
+	// Di mode diverse, pilih dari kandidat terbaik yang strukturnya saling beda
+	ranked := result.Recipes
+	var spread float64
+	if req.Diverse {
+		recipes := make([]map[string]util.Element, len(ranked))
+		for i, candidate := range ranked {
+			recipes[i] = candidate.Recipe
+		}
+		var selected []int
+		selected, spread = req.selectDiverse(recipes)
+		ranked = make([]util.RankedRecipe, 0, len(selected))
+		for _, idx := range selected {
+			ranked = append(ranked, result.Recipes[idx])
+		}
+	}
+
+	response := TreeResponse{
+		TreeData:    []*util.Node{},
+		TimeTaken:   elapsed.String(),
+		NodeVisited: result.Expanded,
+		Metric:      string(metric),
+		Scores:      []int{},
+		Spread:      spread,
+		Stats:       result.Stats,
+	}
+	recipes := make([]map[string]util.Element, 0, len(ranked))
+	for _, candidate := range ranked {
+		tree, _ := util.BuildTree(req.NamaResep, candidate.Recipe)
+		response.TreeData = append(response.TreeData, tree)
+		response.Scores = append(response.Scores, candidate.Score)
+		recipes = append(recipes, candidate.Recipe)
+	}
+	response.SearchID = recentSearches.Store(req.NamaResep, recipes)
+
+	req.writeTrees(w, ds, policy, response)
+}
+
+// reactionAlgorithm satu-satunya algoritma di dataset yang punya reaction.
+// Searcher terdaftar cuma ngerti Pair, jadi gak bisa dipake di dataset kayak gitu.
+const reactionAlgorithm = "Reactions"
+
+// validateReactions nolak opsi yang gak dipake MultipleReactions, daripada diabaikan diem-diem
+func (req SearchRequest) validateReactions() error {
+	switch {
+	case req.Algoritma != "" && req.Algoritma != reactionAlgorithm:
+		return fmt.Errorf("algorithm %q is not supported for datasets with reactions; use %q", req.Algoritma, reactionAlgorithm)
+	case req.RankBy != "" || req.Diverse:
+		return fmt.Errorf("rankBy and diverse are not supported for datasets with reactions")
+	case req.MaxFrontier != 0 || req.Overflow != "" || req.BeamWidth != 0:
+		return fmt.Errorf("maxFrontier, overflow and beamWidth are not supported for datasets with reactions")
+	}
+	return nil
+}
+
+// reactionSearch nyari resep di dataset yang punya reaction pake MultipleReactions;
+// k-best, diverse dan /api/diff belum dukung reaction.
+func reactionSearch(w http.ResponseWriter, req SearchRequest, ds *util.Dataset, policy util.ValidityPolicy) {
+	if err := req.validateReactions(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	result := util.MultipleReactions(req.NamaResep, ds, policy, req.MaksimalResep)
+	elapsed := time.Since(start)
+
+	trees, nodeVisited := util.BuildMultipleReactionTrees(req.NamaResep, result)
+	req.writeTrees(w, ds, policy, TreeResponse{
+		TreeData:    trees,
+		TimeTaken:   elapsed.String(),
+		NodeVisited: nodeVisited,
+		Stats:       result.Stats,
+	})
+}
+
+// policiesHandler ngasih daftar ValidityPolicy yang bisa dipilih lewat field policy
+func policiesHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSON(w, struct {
+		Default  string   `json:"default"`
+		Policies []string `json:"policies"`
+	}{
+		Default:  util.DefaultPolicy.Name(),
+		Policies: util.PolicyNames(),
+	})
+}
+
+// algorithmsHandler ngasih daftar algoritma terdaftar beserta kemampuan dan
+// opsinya, biar frontend bisa nyusun pilihan algoritma sendiri
+func algorithmsHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSON(w, struct {
+		Default    string              `json:"default"`
+		Algorithms []util.SearcherInfo `json:"algorithms"`
+	}{
+		Default:    util.DefaultSearcherName,
+		Algorithms: util.SearcherInfos(),
+	})
+}
+
 func main() {
 	// Ensure data directory exists
 	os.MkdirAll("data", os.ModePerm)
 
 	// Pre-load the recipe data when the server starts
-	loadRecipeData()
+	loadDatasets()
+	loadIconStore()
+	batches = newBatchQueue(batchRunners, maxPendingBatches)
 
 	http.HandleFunc("/api/search", searchHandler)
+	http.HandleFunc("/api/batch", batchHandler)
+	http.HandleFunc("/api/batch/{id}", batchStatusHandler)
+	http.HandleFunc("/api/batch/{id}/cancel", batchCancelHandler)
+	http.HandleFunc("/api/batch/{id}/results", batchResultsHandler)
+	http.HandleFunc("/api/analytics", leaderboardHandler)
+	http.HandleFunc("/api/analytics/{element}", analyticsHandler)
+	http.HandleFunc("/api/catalog", catalogHandler)
+	http.HandleFunc("/api/diff", diffHandler)
+	http.HandleFunc("/api/verify", verifyHandler)
+	http.HandleFunc("/api/policies", policiesHandler)
+	http.HandleFunc("/api/algorithms", algorithmsHandler)
+	http.HandleFunc("/api/datasets", datasetsHandler)
+	http.HandleFunc("/api/icons/{element}", iconHandler)
 	log.Println("Server running on http://localhost:8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
-}
\ No newline at end of file
+}
@@ -0,0 +1,6 @@
+//go:build !embedsnapshot
+
+package main
+
+// embeddedSnapshot kosong kalo gak dibuild pake -tags embedsnapshot (lihat embed.go)
+var embeddedSnapshot []byte
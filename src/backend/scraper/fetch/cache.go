@@ -0,0 +1,73 @@
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheMeta validator yang disimpen di samping body buat conditional GET
+type cacheMeta struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	StoredAt     time.Time `json:"storedAt"`
+}
+
+// cachePaths file body dan meta buat url, namanya hash url biar aman di filesystem
+func (c *Client) cachePaths(url string) (string, string) {
+	sum := sha256.Sum256([]byte(url))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.config.CacheDir, name+".body"), filepath.Join(c.config.CacheDir, name+".json")
+}
+
+// loadCache ngembaliin body dan meta yang tersimpan, atau nil kalo gak ada / gak bisa dipake
+func (c *Client) loadCache(url string) ([]byte, *cacheMeta) {
+	if c.config.CacheDir == "" {
+		return nil, nil
+	}
+	bodyPath, metaPath := c.cachePaths(url)
+
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil
+	}
+	var meta cacheMeta
+	if err := json.Unmarshal(data, &meta); err != nil || meta.URL != url {
+		return nil, nil
+	}
+	// Tanpa validator gak ada yang bisa dikirim ke server, jadi cache-nya gak berguna
+	if meta.ETag == "" && meta.LastModified == "" {
+		return nil, nil
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil
+	}
+	return body, &meta
+}
+
+// storeCache nyimpen response yang punya validator. Gagal nyimpen cache gak dianggap error.
+func (c *Client) storeCache(url string, body []byte, meta cacheMeta) {
+	if c.config.CacheDir == "" || (meta.ETag == "" && meta.LastModified == "") {
+		return
+	}
+	if err := os.MkdirAll(c.config.CacheDir, 0o755); err != nil {
+		return
+	}
+	bodyPath, metaPath := c.cachePaths(url)
+
+	meta.StoredAt = time.Now().UTC()
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return
+	}
+	// Body dulu, meta belakangan: meta tanpa body baru dianggap cache kosong
+	if writeFileAtomic(bodyPath, body) != nil {
+		return
+	}
+	writeFileAtomic(metaPath, data)
+}
@@ -0,0 +1,46 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Errors ngumpulin error dari banyak request (misalnya download ikon) biar satu
+// kegagalan gak ngebatalin semuanya. Aman dipake dari banyak goroutine.
+type Errors struct {
+	mutex  sync.Mutex
+	errors []error
+}
+
+// Add nyatet err kalo gak nil
+func (e *Errors) Add(err error) {
+	if err == nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.errors = append(e.errors, err)
+}
+
+// Addf nyatet error dengan konteks tambahan
+func (e *Errors) Addf(err error, format string, args ...any) {
+	if err == nil {
+		return
+	}
+	e.Add(fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err))
+}
+
+// Len jumlah error yang kecatet
+func (e *Errors) Len() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return len(e.errors)
+}
+
+// Err semua error digabung (errors.Join), nil kalo gak ada
+func (e *Errors) Err() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return errors.Join(e.errors...)
+}
@@ -0,0 +1,302 @@
+// Package fetch is the HTTP client shared by the scrapers: timeouts, retries with
+// exponential backoff, a concurrency limit, a minimum interval between requests
+// and an on-disk cache that revalidates with ETag / Last-Modified.
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Config ngatur perilaku Client. Nilai nol berarti fitur itu mati
+// (kecuali Timeout dan UserAgent yang diisi DefaultConfig).
+type Config struct {
+	Timeout       time.Duration     // Batas waktu satu percobaan request
+	MaxRetries    int               // Berapa kali dicoba ulang setelah percobaan pertama gagal
+	BaseBackoff   time.Duration     // Jeda sebelum retry pertama, dobel tiap retry
+	MaxBackoff    time.Duration     // Jeda maksimal antar retry
+	MaxConcurrent int               // Request yang boleh jalan barengan
+	MinInterval   time.Duration     // Jarak minimal antar mulainya request
+	UserAgent     string            // Dikirim di tiap request
+	CacheDir      string            // Folder cache HTTP, kosong berarti tanpa cache
+	Transport     http.RoundTripper // Override transport, misalnya buat ngarahin ke httptest
+}
+
+// DefaultConfig setelan yang sopan buat wiki fandom
+func DefaultConfig() Config {
+	return Config{
+		Timeout:       30 * time.Second,
+		MaxRetries:    3,
+		BaseBackoff:   500 * time.Millisecond,
+		MaxBackoff:    10 * time.Second,
+		MaxConcurrent: 8,
+		MinInterval:   50 * time.Millisecond,
+		UserAgent:     "little-alchemy-recipe-finder/1.0 (+https://little-alchemy.fandom.com)",
+	}
+}
+
+// StatusError response dengan status yang bukan 2xx/304
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("GET %s: %s", e.URL, e.Status)
+}
+
+// retryable 5xx dan 429 biasanya sementara, 4xx lain gak bakal berubah kalo diulang
+func (e *StatusError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Client aman dipake dari banyak goroutine
+type Client struct {
+	config Config
+	http   *http.Client
+	slots  chan struct{}
+
+	rateMutex sync.Mutex
+	nextStart time.Time
+}
+
+// New bikin Client dari config
+func New(config Config) *Client {
+	client := &Client{
+		config: config,
+		http:   &http.Client{Timeout: config.Timeout, Transport: config.Transport},
+	}
+	if config.MaxConcurrent > 0 {
+		client.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+	return client
+}
+
+// Get ngambil body url, pake cache kalo server bilang gak berubah (304)
+func (c *Client) Get(ctx context.Context, url string) ([]byte, error) {
+	cached, meta := c.loadCache(url)
+
+	var lastErr error
+	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff(attempt, lastErr)); err != nil {
+				return nil, err
+			}
+		}
+
+		body, fresh, err := c.do(ctx, url, meta)
+		if err == nil {
+			if fresh == nil {
+				// 304: isi cache masih valid
+				return cached, nil
+			}
+			c.storeCache(url, body, *fresh)
+			return body, nil
+		}
+		lastErr = err
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && !statusErr.retryable() {
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// Document Get lalu parse jadi dokumen goquery
+func (c *Client) Document(ctx context.Context, url string) (*goquery.Document, error) {
+	body, err := c.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", url, err)
+	}
+	return doc, nil
+}
+
+// Download Get lalu simpen ke path. File lama cuma diganti kalo download-nya sukses.
+func (c *Client) Download(ctx context.Context, url, path string) error {
+	body, err := c.Get(ctx, url)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, body)
+}
+
+// do satu percobaan request. fresh nil berarti 304 Not Modified.
+func (c *Client) do(ctx context.Context, url string, meta *cacheMeta) ([]byte, *cacheMeta, error) {
+	if err := c.acquire(ctx); err != nil {
+		return nil, nil, err
+	}
+	defer c.release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+	if meta != nil {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && meta != nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil, nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, resp.Body)
+		return nil, nil, &retryAfterError{
+			StatusError: &StatusError{URL: url, StatusCode: resp.StatusCode, Status: resp.Status},
+			after:       parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", url, err)
+	}
+	return body, &cacheMeta{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// acquire nunggu slot concurrency dan giliran rate limit
+func (c *Client) acquire(ctx context.Context) error {
+	if c.slots != nil {
+		select {
+		case c.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if c.config.MinInterval <= 0 {
+		return nil
+	}
+	c.rateMutex.Lock()
+	now := time.Now()
+	start := c.nextStart
+	if start.Before(now) {
+		start = now
+	}
+	c.nextStart = start.Add(c.config.MinInterval)
+	c.rateMutex.Unlock()
+
+	if err := sleep(ctx, time.Until(start)); err != nil {
+		c.release()
+		return err
+	}
+	return nil
+}
+
+func (c *Client) release() {
+	if c.slots != nil {
+		<-c.slots
+	}
+}
+
+// backoff jeda sebelum percobaan ke-attempt: eksponensial + jitter, atau Retry-After dari server
+func (c *Client) backoff(attempt int, lastErr error) time.Duration {
+	var retryErr *retryAfterError
+	if errors.As(lastErr, &retryErr) && retryErr.after > 0 {
+		// MaxBackoff 0 berarti tanpa batas, sama kayak di bawah
+		if c.config.MaxBackoff > 0 {
+			return min(retryErr.after, c.config.MaxBackoff)
+		}
+		return retryErr.after
+	}
+
+	delay := c.config.BaseBackoff << (attempt - 1)
+	if c.config.MaxBackoff > 0 && (delay > c.config.MaxBackoff || delay <= 0) {
+		delay = c.config.MaxBackoff
+	}
+	if delay > 0 {
+		delay += time.Duration(rand.Int63n(int64(delay)/4 + 1))
+	}
+	return delay
+}
+
+// retryAfterError StatusError plus Retry-After dari server (kalo ada)
+type retryAfterError struct {
+	*StatusError
+	after time.Duration
+}
+
+func (e *retryAfterError) Unwrap() error { return e.StatusError }
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fetch-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
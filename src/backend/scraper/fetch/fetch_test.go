@@ -0,0 +1,235 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testConfig config cepet buat test: backoff kecil, tanpa rate limit
+func testConfig() Config {
+	return Config{
+		Timeout:     5 * time.Second,
+		MaxRetries:  3,
+		BaseBackoff: 10 * time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+	}
+}
+
+func TestGetRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	body, err := New(testConfig()).Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("body = %q, want %q", body, "ok")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("server saw %d requests, want 3", got)
+	}
+	// Backoff 10ms lalu 20ms (plus jitter)
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("retries took %v, want at least 30ms of backoff", elapsed)
+	}
+}
+
+func TestGetGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "broken", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := testConfig()
+	_, err := New(config).Get(context.Background(), server.URL)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want StatusError 500", err)
+	}
+	if got := calls.Load(); got != int32(config.MaxRetries+1) {
+		t.Errorf("server saw %d requests, want %d", got, config.MaxRetries+1)
+	}
+}
+
+func TestGetDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	_, err := New(testConfig()).Get(context.Background(), server.URL)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("err = %v, want StatusError 404", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+}
+
+func TestGetHonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	if _, err := New(testConfig()).Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+}
+
+func TestBackoffRetryAfterIsCapped(t *testing.T) {
+	client := New(Config{BaseBackoff: time.Millisecond, MaxBackoff: 3 * time.Second})
+	err := &retryAfterError{StatusError: &StatusError{StatusCode: http.StatusTooManyRequests}, after: time.Minute}
+	if got := client.backoff(1, err); got != 3*time.Second {
+		t.Errorf("backoff = %v, want MaxBackoff 3s", got)
+	}
+
+	uncapped := New(Config{BaseBackoff: time.Millisecond})
+	if got := uncapped.backoff(1, err); got != time.Minute {
+		t.Errorf("backoff without MaxBackoff = %v, want the full Retry-After 1m", got)
+	}
+
+	date := time.Now().Add(2 * time.Hour).UTC().Format(http.TimeFormat)
+	if after := parseRetryAfter(date); after < time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %v, want about 2h", date, after)
+	}
+	if after := parseRetryAfter("soon"); after != 0 {
+		t.Errorf("parseRetryAfter(%q) = %v, want 0", "soon", after)
+	}
+}
+
+func TestGetRevalidatesCache(t *testing.T) {
+	for _, validator := range []string{"ETag", "Last-Modified"} {
+		t.Run(validator, func(t *testing.T) {
+			const etag, modified = `"v1"`, "Mon, 02 Jan 2006 15:04:05 GMT"
+			var full, notModified atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("If-None-Match") == etag || r.Header.Get("If-Modified-Since") == modified {
+					notModified.Add(1)
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				full.Add(1)
+				if validator == "ETag" {
+					w.Header().Set("ETag", etag)
+				} else {
+					w.Header().Set("Last-Modified", modified)
+				}
+				w.Write([]byte("cached body"))
+			}))
+			defer server.Close()
+
+			config := testConfig()
+			config.CacheDir = t.TempDir()
+			for i := 0; i < 3; i++ {
+				// Client baru tiap kali: cache-nya harus kebaca dari disk
+				body, err := New(config).Get(context.Background(), server.URL)
+				if err != nil {
+					t.Fatalf("Get #%d: %v", i, err)
+				}
+				if string(body) != "cached body" {
+					t.Fatalf("Get #%d body = %q", i, body)
+				}
+			}
+			if full.Load() != 1 || notModified.Load() != 2 {
+				t.Errorf("server sent %d full responses and %d 304s, want 1 and 2", full.Load(), notModified.Load())
+			}
+		})
+	}
+}
+
+func TestGetWithoutCacheIgnoresValidators(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("client sent If-None-Match without a cache")
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	client := New(testConfig())
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(context.Background(), server.URL); err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("server saw %d requests, want 2", calls.Load())
+	}
+}
+
+func TestErrorsAggregates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var errs Errors
+	if errs.Err() != nil || errs.Len() != 0 {
+		t.Fatalf("empty Errors: Err() = %v, Len() = %d", errs.Err(), errs.Len())
+	}
+
+	client := New(testConfig())
+	paths := []string{"/a", "/missing", "/b", "/missing", "/c"}
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Get(context.Background(), server.URL+path)
+			errs.Addf(err, "icon %s", path)
+		}()
+	}
+	wg.Wait()
+	errs.Add(nil)
+
+	if errs.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", errs.Len())
+	}
+	err := errs.Err()
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Err() = %v, want it to wrap a 404 StatusError", err)
+	}
+	if !strings.Contains(err.Error(), "icon /missing") {
+		t.Errorf("Err() = %q, want the Addf context", err)
+	}
+}
@@ -0,0 +1,181 @@
+package scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"backend/util"
+)
+
+// RecipeSchemaVersion is the version written by WriteRecipes.
+// Version 1 is the legacy bare array of RecipeJSON.
+const RecipeSchemaVersion = 2
+
+// Pages the recipe data is scraped from
+const (
+	ElementsURL         = "https://little-alchemy.fandom.com/wiki/Elements_(Little_Alchemy_2)"
+	MythsAndMonstersURL = "https://little-alchemy.fandom.com/wiki/Category:Myths_and_Monsters"
+)
+
+// RecipeFileHeader describes where a v2 recipe file came from.
+// ContentHash is "sha256:<hex>" over the compact JSON encoding of Elements.
+type RecipeFileHeader struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	ScrapedAt     time.Time `json:"scrapedAt"`
+	Source        string    `json:"source"`
+	ElementCount  int       `json:"elementCount"`
+	ContentHash   string    `json:"contentHash"`
+}
+
+// RecipeElement is one element in a v2 file. Pairs are canonical unordered
+// pairs ([A, B] with A <= B), so A+B and B+A are stored only once.
+// Reactions holds recipes that don't fit a pair (more than two ingredients or
+// more than one product); their products always start with Result.
+type RecipeElement struct {
+	Result    string          `json:"result"`
+	Asset     string          `json:"asset,omitempty"`
+	Tier      int             `json:"tier"`
+	Pairs     [][2]string     `json:"pairs"`
+	Reactions []util.Reaction `json:"reactions,omitempty"`
+}
+
+// RecipeFile is the v2 on-disk format
+type RecipeFile struct {
+	Header   RecipeFileHeader `json:"header"`
+	Elements []RecipeElement  `json:"elements"`
+}
+
+// DecodeRecipeJSON reads v1 or v2 recipe data and returns it in the v1 shape,
+// with both orderings of every pair, so callers don't care which version is on disk.
+// The header is nil for v1 data.
+func DecodeRecipeJSON(data []byte) ([]RecipeJSON, *RecipeFileHeader, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil, fmt.Errorf("empty recipe data")
+	}
+
+	// v1 is a bare array
+	if trimmed[0] == '[' {
+		var recipes []RecipeJSON
+		if err := json.Unmarshal(trimmed, &recipes); err != nil {
+			return nil, nil, err
+		}
+		return recipes, nil, nil
+	}
+
+	var file RecipeFile
+	if err := json.Unmarshal(trimmed, &file); err != nil {
+		return nil, nil, err
+	}
+	if file.Header.SchemaVersion != RecipeSchemaVersion {
+		return nil, nil, fmt.Errorf("unsupported recipe schema version %d", file.Header.SchemaVersion)
+	}
+	if file.Header.ElementCount != len(file.Elements) {
+		return nil, nil, fmt.Errorf("recipe header says %d elements, file has %d", file.Header.ElementCount, len(file.Elements))
+	}
+	hash, err := contentHash(file.Elements)
+	if err != nil {
+		return nil, nil, err
+	}
+	if file.Header.ContentHash != hash {
+		return nil, nil, fmt.Errorf("recipe content hash mismatch: header %s, content %s", file.Header.ContentHash, hash)
+	}
+
+	recipes := make([]RecipeJSON, 0, len(file.Elements))
+	for _, elem := range file.Elements {
+		recipe := RecipeJSON{Result: elem.Result, Asset: elem.Asset, Tier: elem.Tier, Reactions: elem.Reactions}
+		for _, pair := range elem.Pairs {
+			recipe.Combinations = append(recipe.Combinations, util.Pair{First: pair[0], Second: pair[1]})
+			if pair[0] != pair[1] {
+				recipe.Combinations = append(recipe.Combinations, util.Pair{First: pair[1], Second: pair[0]})
+			}
+		}
+		recipes = append(recipes, recipe)
+	}
+	return recipes, &file.Header, nil
+}
+
+// NewRecipeFile converts v1-shaped recipes into a v2 file, folding mirrored pairs
+// and filling in the header
+func NewRecipeFile(recipes []RecipeJSON, source string, scrapedAt time.Time) (RecipeFile, error) {
+	elements := make([]RecipeElement, 0, len(recipes))
+	for _, recipe := range recipes {
+		elem := RecipeElement{Result: recipe.Result, Asset: recipe.Asset, Tier: recipe.Tier, Pairs: [][2]string{}}
+		seen := make(map[[2]string]bool)
+		for _, combo := range recipe.Combinations {
+			pair := [2]string{combo.First, combo.Second}
+			if pair[1] < pair[0] {
+				pair = [2]string{combo.Second, combo.First}
+			}
+			if !seen[pair] {
+				seen[pair] = true
+				elem.Pairs = append(elem.Pairs, pair)
+			}
+		}
+		for _, reaction := range recipe.Reactions {
+			elem.Reactions = append(elem.Reactions, normalizeReaction(recipe.Result, reaction))
+		}
+		elements = append(elements, elem)
+	}
+
+	hash, err := contentHash(elements)
+	if err != nil {
+		return RecipeFile{}, err
+	}
+	return RecipeFile{
+		Header: RecipeFileHeader{
+			SchemaVersion: RecipeSchemaVersion,
+			ScrapedAt:     scrapedAt.UTC(),
+			Source:        source,
+			ElementCount:  len(elements),
+			ContentHash:   hash,
+		},
+		Elements: elements,
+	}, nil
+}
+
+// WriteRecipes writes recipes to w in the v2 format
+func WriteRecipes(w io.Writer, recipes []RecipeJSON, source string, scrapedAt time.Time) error {
+	file, err := NewRecipeFile(recipes, source, scrapedAt)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(file)
+}
+
+// WriteRecipesFile is WriteRecipes into a file, replacing it only once the write succeeded
+func WriteRecipesFile(filename string, recipes []RecipeJSON, source string, scrapedAt time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".recipes-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WriteRecipes(tmp, recipes, source, scrapedAt); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// contentHash hashes the compact JSON of the elements, independent of indentation
+func contentHash(elements []RecipeElement) (string, error) {
+	data, err := json.Marshal(elements)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
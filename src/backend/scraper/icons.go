@@ -0,0 +1,283 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"backend/scraper/fetch"
+)
+
+// IconEntry ikon satu elemen di IconStore
+type IconEntry struct {
+	Hash        string `json:"hash"`        // sha256 isi file, sekaligus nama objeknya
+	ContentType string `json:"contentType"` // Biasanya image/svg+xml
+	Source      string `json:"source"`      // URL asal, dipake buat skip download yang gak berubah
+}
+
+// IconStore nyimpen ikon secara content-addressed: isi file disimpen sekali di
+// objects/<hash[:2]>/<hash>, index.json nyatet elemen mana pake hash yang mana.
+// Elemen dengan ikon identik cuma makan satu file.
+type IconStore struct {
+	dir   string
+	mutex sync.RWMutex
+	index map[string]IconEntry
+}
+
+const iconIndexFile = "index.json"
+
+// NewIconStore store kosong di dir, index lama (kalo ada) gak dibaca
+func NewIconStore(dir string) *IconStore {
+	return &IconStore{dir: dir, index: make(map[string]IconEntry)}
+}
+
+// OpenIconStore buka (atau siapin) store di dir. Folder-nya baru dibikin pas ada yang disimpen.
+func OpenIconStore(dir string) (*IconStore, error) {
+	store := NewIconStore(dir)
+
+	data, err := os.ReadFile(filepath.Join(dir, iconIndexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.index); err != nil {
+		return nil, fmt.Errorf("icon index: %w", err)
+	}
+	return store, nil
+}
+
+// Len jumlah elemen yang punya ikon
+func (s *IconStore) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.index)
+}
+
+// Lookup entry dan path file ikon buat element
+func (s *IconStore) Lookup(element string) (IconEntry, string, bool) {
+	s.mutex.RLock()
+	entry, exists := s.index[element]
+	s.mutex.RUnlock()
+	if !exists {
+		return IconEntry{}, "", false
+	}
+	return entry, s.objectPath(entry.Hash), true
+}
+
+// Put nyimpen ikon element. Objeknya cuma ditulis kalo hash-nya belum ada.
+func (s *IconStore) Put(element, source string, data []byte) (IconEntry, error) {
+	sum := sha256.Sum256(data)
+	entry := IconEntry{
+		Hash:        hex.EncodeToString(sum[:]),
+		ContentType: iconContentType(data),
+		Source:      source,
+	}
+
+	path := s.objectPath(entry.Hash)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return entry, err
+		}
+		if err := writeFileAtomic(path, data); err != nil {
+			return entry, err
+		}
+	}
+
+	s.mutex.Lock()
+	s.index[element] = entry
+	s.mutex.Unlock()
+	return entry, nil
+}
+
+// Save nulis index.json
+func (s *IconStore) Save() error {
+	s.mutex.RLock()
+	data, err := json.MarshalIndent(s.index, "", "  ")
+	s.mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.dir, iconIndexFile), append(data, '\n'))
+}
+
+// Prune hapus objek yang udah gak dipake elemen mana pun, ngembaliin jumlah yang dihapus
+func (s *IconStore) Prune() (int, error) {
+	s.mutex.RLock()
+	used := make(map[string]bool, len(s.index))
+	for _, entry := range s.index {
+		used[entry.Hash] = true
+	}
+	s.mutex.RUnlock()
+
+	removed := 0
+	err := filepath.WalkDir(filepath.Join(s.dir, "objects"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || used[d.Name()] {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+func (s *IconStore) objectPath(hash string) string {
+	return filepath.Join(s.dir, "objects", hash[:2], hash)
+}
+
+// iconContentType SVG gak dikenalin http.DetectContentType (kebacanya text/xml)
+func iconContentType(data []byte) string {
+	if bytes.Contains(data[:min(len(data), 1024)], []byte("<svg")) {
+		return "image/svg+xml"
+	}
+	return http.DetectContentType(data)
+}
+
+// IconStats hasil DownloadIcons
+type IconStats struct {
+	Downloaded int `json:"downloaded"`
+	Unchanged  int `json:"unchanged"` // Source-nya sama kayak yang udah di store
+	Failed     int `json:"failed"`
+	Missing    int `json:"missing"` // Elemen tanpa Asset
+}
+
+// iconWorkers download ikon yang jalan barengan, dibatasin lagi sama FetchClient
+const iconWorkers = 8
+
+// DownloadIcons tahap opsional pipeline scraper: download ikon tiap RecipeJSON
+// (key-nya Result, URL-nya Asset) ke store. Ikon yang Source-nya gak berubah di-skip.
+// Download yang gagal dikumpulin jadi satu error, sisanya tetap disimpen.
+func DownloadIcons(ctx context.Context, client *fetch.Client, recipes []RecipeJSON, store *IconStore) (IconStats, error) {
+	var stats IconStats
+	var statsMutex sync.Mutex
+	var failures fetch.Errors
+
+	jobs := make(chan RecipeJSON)
+	var wg sync.WaitGroup
+	for w := 0; w < iconWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for recipe := range jobs {
+				data, err := client.Get(ctx, recipe.Asset)
+				if err == nil {
+					_, err = store.Put(recipe.Result, recipe.Asset, data)
+				}
+
+				statsMutex.Lock()
+				if err != nil {
+					stats.Failed++
+				} else {
+					stats.Downloaded++
+				}
+				statsMutex.Unlock()
+				failures.Addf(err, "icon for %s", recipe.Result)
+			}
+		}()
+	}
+
+	seen := make(map[string]bool)
+	for _, recipe := range recipes {
+		if seen[recipe.Result] {
+			continue
+		}
+		seen[recipe.Result] = true
+
+		if recipe.Asset == "" {
+			stats.Missing++
+			continue
+		}
+		if entry, _, exists := store.Lookup(recipe.Result); exists && entry.Source == recipe.Asset {
+			stats.Unchanged++
+			continue
+		}
+		jobs <- recipe
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := store.Save(); err != nil {
+		failures.Addf(err, "save icon index")
+	}
+	return stats, failures.Err()
+}
+
+// ImportIcons masukin file "<Elemen>.<ext>" dari dir (misalnya output iconscrape lama) ke store.
+// sources URL Asset tiap elemen (lihat IconSources); dicatet sebagai Source biar
+// DownloadIcons berikutnya gak download ulang ikon yang udah di-import.
+func ImportIcons(dir string, store *IconStore, sources map[string]string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	imported := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		element := name[:len(name)-len(filepath.Ext(name))]
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return imported, err
+		}
+		if _, err := store.Put(element, sources[element], data); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+	return imported, store.Save()
+}
+
+// IconSources URL Asset tiap elemen di recipes, kayak yang dipake DownloadIcons
+func IconSources(recipes []RecipeJSON) map[string]string {
+	sources := make(map[string]string)
+	for _, recipe := range recipes {
+		if _, seen := sources[recipe.Result]; !seen && recipe.Asset != "" {
+			sources[recipe.Result] = recipe.Asset
+		}
+	}
+	return sources
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -1,136 +1,63 @@
+// iconscrape download ikon semua elemen di recipes.json ke icon store
+// (data/icons) yang dilayani backend di /api/icons/{element}.
+//
+// go.mod ada di src/backend, jadi jalanin dari situ dan arahin path-nya ke data/ di root repo:
+//
+//	cd src/backend && go run ./scraper/iconscrape -recipes ../../data/recipes.json -dir ../../data/icons
+//	cd src/backend && go run ./scraper/iconscrape -recipes ../../data/recipes.json -dir ../../data/icons -import old/icons
+//
+// Ikon yang URL asalnya gak berubah gak didownload ulang.
 package main
 
 import (
-	"encoding/json"
-	"io"
+	"context"
+	"flag"
 	"log"
-	"net/http"
 	"os"
-	"path/filepath"
-	"strings"
-	"sync"
 
-	"github.com/PuerkitoBio/goquery"
+	"backend/scraper"
 )
 
-// struktur data untuk menyimpan nama elemen beserta recipe penyusunnya
-type Element struct {
-	Name    string     `json:"element"`
-	Recipes [][]string `json:"recipes"`
-}
-
-// fungsi untuk donlot file, di sini dipake untuk donlot SVG
-func donlotFile(url, path string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	out, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	return err
-}
-
 func main() {
-	os.Mkdir("icons", 0755)
+	recipeFile := flag.String("recipes", "data/recipes.json", "recipe file whose Asset URLs are downloaded (or recorded as the source of imported icons)")
+	iconDir := flag.String("dir", "data/icons", "icon store directory")
+	importDir := flag.String("import", "", "import <Element>.svg files from this directory instead of downloading")
+	prune := flag.Bool("prune", false, "remove icon objects no element refers to anymore")
+	flag.Parse()
 
-	// get request ke halaman wiki Little Alchemy 2
-	res, err := http.Get("https://little-alchemy.fandom.com/wiki/Elements_(Little_Alchemy_2)")
+	store, err := scraper.OpenIconStore(*iconDir)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer res.Body.Close()
 
-	// parsing HTML-nya
-	doc, err := goquery.NewDocumentFromReader(res.Body)
+	recipes, err := scraper.LoadRecipeJSON(*recipeFile)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	var elements []Element
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	inElements := make(map[string]bool)
-
-	// ambil semua elemen dari tabel
-	doc.Find("table.list-table tr").Each(func(i int, row *goquery.Selection) {
-		if i == 0 {
-			return
+	if *importDir != "" {
+		// URL asal dari recipes.json dicatet biar download berikutnya bisa skip ikon ini
+		imported, err := scraper.ImportIcons(*importDir, store, scraper.IconSources(recipes))
+		if err != nil {
+			log.Fatal(err)
 		}
-
-		// ambil nama elemen di kolom pertama
-		element := strings.TrimSpace(row.Find("td:nth-child(1) a").Text())
-		if element == "" {
-			return
+		log.Printf("Imported %d icons into %s", imported, *iconDir)
+	} else {
+		stats, err := scraper.DownloadIcons(context.Background(), scraper.FetchClient, recipes, store)
+		log.Printf("Icons: %d downloaded, %d unchanged, %d failed, %d without asset",
+			stats.Downloaded, stats.Unchanged, stats.Failed, stats.Missing)
+		if err != nil {
+			// laporin semua download yang gagal sekaligus
+			log.Printf("%d icon downloads failed:\n%v", stats.Failed, err)
+			defer os.Exit(1)
 		}
-
-		// cek apakah elemen sudah diproses
-		mu.Lock()
-		if inElements[element] {
-			mu.Unlock()
-			return
-		}
-		inElements[element] = true
-		mu.Unlock()
-
-		// ambil URL dari SVG di kolom pertama
-		svgURL := ""
-		imgTag := row.Find("td:nth-child(1) .icon-hover a").First()
-		if imgHref, exists := imgTag.Attr("href"); exists {
-			svgURL = imgHref
-		}
-
-		// ambil semua recipe dari kolom kedua
-		var recipes [][]string
-		row.Find("td:nth-child(2) li").Each(func(j int, li *goquery.Selection) {
-			recipe := strings.TrimSpace(li.Text())
-			parts := strings.Split(recipe, " + ")
-			if len(parts) == 2 {
-				recipes = append(recipes, []string{strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])})
-			}
-		})
-
-		wg.Add(1)
-
-		go func(element, svgURL string, recipes [][]string) {
-			defer wg.Done()
-			// donlot SVG
-			svgPath := filepath.Join("icons", element+".svg")
-			if err := donlotFile(svgURL, svgPath); err != nil {
-				log.Printf("Failed to download SVG for %s: %v", element, err)
-				return
-			}
-
-			// tambahin elemen ke slice
-			mu.Lock()
-			elements = append(elements, Element{
-				Name:    element,
-				Recipes: recipes,
-			})
-			mu.Unlock()
-		}(element, svgURL, recipes)
-	})
-
-	// tunggu semua goroutine selesai
-	wg.Wait()
-
-	// simpen data ke file JSON
-	file, err := os.Create("elements.json")
-	if err != nil {
-		log.Fatal(err)
 	}
-	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "    ")
-	if err := encoder.Encode(elements); err != nil {
-		log.Fatal(err)
+	if *prune {
+		removed, err := store.Prune()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Pruned %d unused icon objects", removed)
 	}
 }
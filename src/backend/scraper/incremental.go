@@ -0,0 +1,308 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+
+	"backend/util"
+)
+
+// TierChange an element whose tier differs between two scrapes
+type TierChange struct {
+	Element string `json:"element"`
+	From    int    `json:"from"`
+	To      int    `json:"to"`
+}
+
+// CombinationChange a combination (unordered, "A+B") or reaction ("A+B+C=X+Y",
+// see util.Reaction.Key) added to or removed from an element
+type CombinationChange struct {
+	Element     string `json:"element"`
+	Ingredients string `json:"ingredients"`
+}
+
+// ChangeReport what changed between the recipe file on disk and a fresh scrape
+type ChangeReport struct {
+	GeneratedAt         time.Time           `json:"generatedAt"`
+	OldElements         int                 `json:"oldElements"`
+	NewElements         int                 `json:"newElements"`
+	OldCombinations     int                 `json:"oldCombinations"`
+	NewCombinations     int                 `json:"newCombinations"`
+	OldReactions        int                 `json:"oldReactions"`
+	NewReactions        int                 `json:"newReactions"`
+	AddedElements       []string            `json:"addedElements"`
+	RemovedElements     []string            `json:"removedElements"`
+	TierChanges         []TierChange        `json:"tierChanges"`
+	AddedCombinations   []CombinationChange `json:"addedCombinations"`
+	RemovedCombinations []CombinationChange `json:"removedCombinations"`
+	AddedReactions      []CombinationChange `json:"addedReactions"`
+	RemovedReactions    []CombinationChange `json:"removedReactions"`
+	AssetChanges        int                 `json:"assetChanges"`
+	Icons               *IconStats          `json:"icons,omitempty"` // Diisi kalo tahap ikon jalan
+	Written             bool                `json:"written"`
+	Reason              string              `json:"reason"` // Kenapa file ditulis atau gak
+}
+
+// HasChanges true kalo ada perbedaan apa pun antara data lama dan baru
+func (r ChangeReport) HasChanges() bool {
+	return len(r.AddedElements) > 0 || len(r.RemovedElements) > 0 || len(r.TierChanges) > 0 ||
+		len(r.AddedCombinations) > 0 || len(r.RemovedCombinations) > 0 ||
+		len(r.AddedReactions) > 0 || len(r.RemovedReactions) > 0 || r.AssetChanges > 0
+}
+
+// SanityThreshold batas perubahan yang masih dianggap wajar. Wiki yang lagi rusak
+// (layout berubah, halaman kepotong) biasanya kelihatan sebagai banyak elemen
+// atau kombinasi yang tiba-tiba hilang atau tier yang kacau, jadi tambahan gak dibatasi.
+type SanityThreshold struct {
+	MaxRemovedElements     float64 // Fraksi elemen lama yang boleh hilang
+	MaxRemovedCombinations float64 // Fraksi kombinasi (dan reaction) lama yang boleh hilang
+	MaxTierChanges         float64 // Fraksi elemen lama yang boleh ganti tier
+}
+
+// DefaultSanityThreshold dipake kalo caller gak ngatur sendiri
+var DefaultSanityThreshold = SanityThreshold{
+	MaxRemovedElements:     0.05,
+	MaxRemovedCombinations: 0.10,
+	MaxTierChanges:         0.20,
+}
+
+// Check ngembaliin error kalo report ngelewatin threshold
+func (t SanityThreshold) Check(report ChangeReport) error {
+	if report.NewElements == 0 {
+		return errors.New("scrape returned no elements")
+	}
+	exceeds := func(count, total int, limit float64) bool {
+		return total > 0 && float64(count)/float64(total) > limit
+	}
+	if exceeds(len(report.RemovedElements), report.OldElements, t.MaxRemovedElements) {
+		return fmt.Errorf("%d of %d elements removed (limit %.0f%%)", len(report.RemovedElements), report.OldElements, t.MaxRemovedElements*100)
+	}
+	if exceeds(len(report.RemovedCombinations), report.OldCombinations, t.MaxRemovedCombinations) {
+		return fmt.Errorf("%d of %d combinations removed (limit %.0f%%)", len(report.RemovedCombinations), report.OldCombinations, t.MaxRemovedCombinations*100)
+	}
+	if exceeds(len(report.RemovedReactions), report.OldReactions, t.MaxRemovedCombinations) {
+		return fmt.Errorf("%d of %d reactions removed (limit %.0f%%)", len(report.RemovedReactions), report.OldReactions, t.MaxRemovedCombinations*100)
+	}
+	if exceeds(len(report.TierChanges), report.OldElements, t.MaxTierChanges) {
+		return fmt.Errorf("%d of %d elements changed tier (limit %.0f%%)", len(report.TierChanges), report.OldElements, t.MaxTierChanges*100)
+	}
+	return nil
+}
+
+// DiffRecipeData bandingin dua versi data resep. Kombinasi dan reaction dibandingin
+// tanpa urutan (A+B = B+A).
+func DiffRecipeData(previous, current []RecipeJSON) ChangeReport {
+	type elementData struct {
+		tier      int
+		asset     string
+		pairs     map[string]bool
+		reactions map[string]bool
+	}
+	index := func(recipes []RecipeJSON) (map[string]*elementData, int, int) {
+		elements := make(map[string]*elementData)
+		pairTotal, reactionTotal := 0, 0
+		for _, recipe := range recipes {
+			data := elements[recipe.Result]
+			if data == nil {
+				data = &elementData{pairs: make(map[string]bool), reactions: make(map[string]bool)}
+				elements[recipe.Result] = data
+			}
+			data.tier = recipe.Tier
+			data.asset = recipe.Asset
+			for _, pair := range recipe.Combinations {
+				key := util.NormalizeIngredients(pair.First, pair.Second)
+				if !data.pairs[key] {
+					data.pairs[key] = true
+					pairTotal++
+				}
+			}
+			for _, reaction := range recipe.Reactions {
+				// Products kosong berarti cuma Result
+				if len(reaction.Products) == 0 {
+					reaction.Products = []string{recipe.Result}
+				}
+				key := reaction.Key()
+				if !data.reactions[key] {
+					data.reactions[key] = true
+					reactionTotal++
+				}
+			}
+		}
+		return elements, pairTotal, reactionTotal
+	}
+
+	oldElements, oldTotal, oldReactions := index(previous)
+	newElements, newTotal, newReactions := index(current)
+
+	report := ChangeReport{
+		GeneratedAt:         time.Now().UTC(),
+		OldElements:         len(oldElements),
+		NewElements:         len(newElements),
+		OldCombinations:     oldTotal,
+		NewCombinations:     newTotal,
+		OldReactions:        oldReactions,
+		NewReactions:        newReactions,
+		AddedElements:       []string{},
+		RemovedElements:     []string{},
+		TierChanges:         []TierChange{},
+		AddedCombinations:   []CombinationChange{},
+		RemovedCombinations: []CombinationChange{},
+		AddedReactions:      []CombinationChange{},
+		RemovedReactions:    []CombinationChange{},
+	}
+
+	for name, before := range oldElements {
+		after, exists := newElements[name]
+		if !exists {
+			report.RemovedElements = append(report.RemovedElements, name)
+			for pair := range before.pairs {
+				report.RemovedCombinations = append(report.RemovedCombinations, CombinationChange{Element: name, Ingredients: pair})
+			}
+			for reaction := range before.reactions {
+				report.RemovedReactions = append(report.RemovedReactions, CombinationChange{Element: name, Ingredients: reaction})
+			}
+			continue
+		}
+		if before.tier != after.tier {
+			report.TierChanges = append(report.TierChanges, TierChange{Element: name, From: before.tier, To: after.tier})
+		}
+		if before.asset != after.asset {
+			report.AssetChanges++
+		}
+		for pair := range before.pairs {
+			if !after.pairs[pair] {
+				report.RemovedCombinations = append(report.RemovedCombinations, CombinationChange{Element: name, Ingredients: pair})
+			}
+		}
+		for reaction := range before.reactions {
+			if !after.reactions[reaction] {
+				report.RemovedReactions = append(report.RemovedReactions, CombinationChange{Element: name, Ingredients: reaction})
+			}
+		}
+	}
+	for name, after := range newElements {
+		before, exists := oldElements[name]
+		if !exists {
+			report.AddedElements = append(report.AddedElements, name)
+		}
+		for pair := range after.pairs {
+			if !exists || !before.pairs[pair] {
+				report.AddedCombinations = append(report.AddedCombinations, CombinationChange{Element: name, Ingredients: pair})
+			}
+		}
+		for reaction := range after.reactions {
+			if !exists || !before.reactions[reaction] {
+				report.AddedReactions = append(report.AddedReactions, CombinationChange{Element: name, Ingredients: reaction})
+			}
+		}
+	}
+
+	sort.Strings(report.AddedElements)
+	sort.Strings(report.RemovedElements)
+	sort.Slice(report.TierChanges, func(i, j int) bool { return report.TierChanges[i].Element < report.TierChanges[j].Element })
+	sortCombinationChanges(report.AddedCombinations)
+	sortCombinationChanges(report.RemovedCombinations)
+	sortCombinationChanges(report.AddedReactions)
+	sortCombinationChanges(report.RemovedReactions)
+	return report
+}
+
+func sortCombinationChanges(changes []CombinationChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Element != changes[j].Element {
+			return changes[i].Element < changes[j].Element
+		}
+		return changes[i].Ingredients < changes[j].Ingredients
+	})
+}
+
+// IncrementalOptions ngatur IncrementalScrape
+type IncrementalOptions struct {
+	Threshold SanityThreshold
+	DryRun    bool   // Cuma bikin report, file gak pernah ditulis
+	Force     bool   // Tulis walaupun ngelewatin threshold
+	IconDir   string // Kalo diisi, ikon ikut didownload ke IconStore di folder ini
+}
+
+// IncrementalScrape scrape ulang wiki lalu bandingin sama filename. File cuma ditulis
+// kalo ada perubahan dan perubahannya lolos threshold. Kalo filename belum ada,
+// semua dianggap elemen baru dan file langsung ditulis.
+func IncrementalScrape(filename string, opts IncrementalOptions) (ChangeReport, error) {
+	old, err := LoadRecipeJSON(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return ChangeReport{}, err
+	}
+
+	combinations := make(map[util.Pair]string)
+	tierMap := make(map[string]int)
+	revCombinations := make(map[string][]util.Pair)
+	_, ordered, err := Scraper(combinations, tierMap, revCombinations, false)
+	if err != nil {
+		return ChangeReport{}, err
+	}
+	fresh := ordered.Recipes()
+
+	report := DiffRecipeData(old, fresh)
+	switch {
+	case opts.DryRun:
+		// Dry run gak nulis apa pun, ikon juga nggak
+		report.Reason = "dry run"
+		if !report.HasChanges() {
+			report.Reason = "dry run, no changes"
+		}
+		return report, nil
+	case !report.HasChanges():
+		// Data resep sama, tapi ikon yang belum ada atau gagal kemarin tetap dilengkapin
+		report.Reason = "no changes"
+		return report, downloadIconStage(&report, fresh, opts)
+	}
+
+	if err := opts.Threshold.Check(report); err != nil {
+		if !opts.Force {
+			report.Reason = "sanity threshold exceeded: " + err.Error()
+			return report, nil
+		}
+		report.Reason = "written despite sanity threshold (forced): " + err.Error()
+	} else {
+		report.Reason = "changes within sanity threshold"
+	}
+
+	if err := WriteRecipesFile(filename, fresh, ElementsURL, time.Now()); err != nil {
+		return report, err
+	}
+	report.Written = true
+
+	return report, downloadIconStage(&report, fresh, opts)
+}
+
+// downloadIconStage tahap ikon opsional, cuma jalan kalo data resepnya diterima
+func downloadIconStage(report *ChangeReport, recipes []RecipeJSON, opts IncrementalOptions) error {
+	if opts.IconDir == "" {
+		return nil
+	}
+	store, err := OpenIconStore(opts.IconDir)
+	if err != nil {
+		return err
+	}
+	stats, err := DownloadIcons(context.Background(), FetchClient, recipes, store)
+	report.Icons = &stats
+	if err != nil {
+		return fmt.Errorf("%d icon downloads failed: %w", stats.Failed, err)
+	}
+	return nil
+}
+
+// WriteChangeReport nyimpen report sebagai JSON
+func WriteChangeReport(filename string, report ChangeReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
+}
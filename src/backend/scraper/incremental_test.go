@@ -0,0 +1,61 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+
+	"backend/util"
+)
+
+func TestDiffRecipeDataReactions(t *testing.T) {
+	previous := []RecipeJSON{
+		{Result: "Steam", Tier: 1, Combinations: []util.Pair{{First: "Water", Second: "Fire"}}},
+		{Result: "Salt", Tier: 2, Reactions: []util.Reaction{
+			{Ingredients: []string{"Water", "Fire", "Earth"}},
+			{Ingredients: []string{"Sea", "Sun", "Air"}, Products: []string{"Salt", "Steam"}},
+		}},
+	}
+
+	// Urutan bahan dan produk beda, Products kosong sama dengan [Result]: gak ada perubahan
+	same := []RecipeJSON{
+		{Result: "Steam", Tier: 1, Combinations: []util.Pair{{First: "Fire", Second: "Water"}}},
+		{Result: "Salt", Tier: 2, Reactions: []util.Reaction{
+			{Ingredients: []string{"Earth", "Fire", "Water"}, Products: []string{"Salt"}},
+			{Ingredients: []string{"Air", "Sea", "Sun"}, Products: []string{"Steam", "Salt"}},
+		}},
+	}
+	if report := DiffRecipeData(previous, same); report.HasChanges() {
+		t.Errorf("reordered reactions reported as changes: %+v", report)
+	}
+
+	current := []RecipeJSON{
+		{Result: "Steam", Tier: 1, Combinations: []util.Pair{{First: "Water", Second: "Fire"}}},
+		{Result: "Salt", Tier: 2, Reactions: []util.Reaction{
+			{Ingredients: []string{"Water", "Fire", "Earth"}},
+			{Ingredients: []string{"Sea", "Sun", "Stone"}},
+		}},
+	}
+	report := DiffRecipeData(previous, current)
+	if !report.HasChanges() {
+		t.Fatalf("changed reactions reported as no changes")
+	}
+	if report.OldReactions != 2 || report.NewReactions != 2 {
+		t.Errorf("reactions %d -> %d, want 2 -> 2", report.OldReactions, report.NewReactions)
+	}
+	wantAdded := []CombinationChange{{Element: "Salt", Ingredients: "Sea+Stone+Sun=Salt"}}
+	wantRemoved := []CombinationChange{{Element: "Salt", Ingredients: "Air+Sea+Sun=Salt+Steam"}}
+	if !reflect.DeepEqual(report.AddedReactions, wantAdded) {
+		t.Errorf("added reactions %v, want %v", report.AddedReactions, wantAdded)
+	}
+	if !reflect.DeepEqual(report.RemovedReactions, wantRemoved) {
+		t.Errorf("removed reactions %v, want %v", report.RemovedReactions, wantRemoved)
+	}
+	if len(report.AddedCombinations) != 0 || len(report.RemovedCombinations) != 0 {
+		t.Errorf("pair combinations changed: +%v -%v", report.AddedCombinations, report.RemovedCombinations)
+	}
+
+	// Setengah reaction hilang ngelewatin batas kombinasi yang hilang
+	if err := DefaultSanityThreshold.Check(report); err == nil {
+		t.Errorf("removing half of the reactions passed the sanity threshold")
+	}
+}
@@ -0,0 +1,91 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// CombinationOverride satu kombinasi di overrides file: ingredients -> result
+type CombinationOverride struct {
+	Result      string    `json:"result"`
+	Ingredients [2]string `json:"ingredients"`
+}
+
+// Overrides koreksi manual buat data wiki, diterapin urut: hapus elemen,
+// hapus kombinasi, tambah kombinasi, lalu set tier.
+//
+//	{
+//	  "removeElements": ["Time"],
+//	  "remove": [{"result": "Fire", "ingredients": ["Fire", "Coal"]}],
+//	  "add": [{"result": "Ruins", "ingredients": ["Castle", "Time"]}],
+//	  "tiers": {"Ruins": 7}
+//	}
+type Overrides struct {
+	RemoveElements []string              `json:"removeElements,omitempty"`
+	Remove         []CombinationOverride `json:"remove,omitempty"`
+	Add            []CombinationOverride `json:"add,omitempty"`
+	Tiers          map[string]int        `json:"tiers,omitempty"`
+}
+
+// Apply nerapin overrides ke set. Hapus sesuatu yang gak ada dianggap error,
+// biar override basi (wiki-nya udah dibenerin) ketahuan.
+func (o Overrides) Apply(set *RecipeSet) error {
+	var errs []error
+	for _, element := range o.RemoveElements {
+		if !set.RemoveElement(element) {
+			errs = append(errs, fmt.Errorf("removeElements: %s does not exist", element))
+		}
+	}
+	for _, combo := range o.Remove {
+		if !set.RemoveCombination(combo.Result, combo.Ingredients[0], combo.Ingredients[1]) {
+			errs = append(errs, fmt.Errorf("remove: %s + %s -> %s does not exist", combo.Ingredients[0], combo.Ingredients[1], combo.Result))
+		}
+	}
+	for _, combo := range o.Add {
+		if combo.Result == "" || combo.Ingredients[0] == "" || combo.Ingredients[1] == "" {
+			errs = append(errs, fmt.Errorf("add: combination needs a result and two ingredients"))
+			continue
+		}
+		set.AddCombination(combo.Result, combo.Ingredients[0], combo.Ingredients[1])
+	}
+	for element, tier := range o.Tiers {
+		set.SetTier(element, tier)
+	}
+	return errors.Join(errs...)
+}
+
+// OverridesSource overrides dari file JSON (format Overrides)
+type OverridesSource struct {
+	Path     string
+	Optional bool
+}
+
+func (s *OverridesSource) Name() string { return "overrides " + s.Path }
+
+func (s *OverridesSource) Fingerprint() ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) && s.Optional {
+		return []byte("missing"), nil
+	}
+	return data, err
+}
+
+func (s *OverridesSource) Apply(ctx context.Context, set *RecipeSet) error {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) && s.Optional {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var overrides Overrides
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return err
+	}
+	return overrides.Apply(set)
+}
@@ -1,24 +1,35 @@
 package scraper
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
-	"net/http"
-	"os"
 	"sort"
 	"strings"
+	"time"
 
+	"backend/scraper/fetch"
 	"backend/util"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+// FetchClient dipake semua request scraper. Cache HTTP-nya bikin scrape ulang
+// cuma download halaman yang berubah.
+var FetchClient = newFetchClient()
+
+func newFetchClient() *fetch.Client {
+	config := fetch.DefaultConfig()
+	config.CacheDir = "data/http-cache"
+	return fetch.New(config)
+}
+
 type RecipeJSON struct {
 	Result       string      `json:"Result"`
 	Asset        string      `json:"Asset"`
 	Tier         int         `json:"Tier"`
 	Combinations []util.Pair `json:"Combinations"`
+	// Resep selain dua bahan -> satu produk. Products kosong berarti cuma Result.
+	Reactions []util.Reaction `json:"Reactions,omitempty"`
 }
 
 // OrderedCombinations holds both the map and the order
@@ -29,8 +40,26 @@ type OrderedCombinations struct {
 
 // OrderedRevCombinations holds both the map and the order
 type OrderedRevCombinations struct {
-	Map   map[string][]util.Pair
-	Order []string // Maintains insertion order
+	Map       map[string][]util.Pair
+	Order     []string                   // Maintains insertion order
+	Tiers     map[string]int             // Tier per result, same map passed to Scraper
+	Assets    map[string]string          // Icon URL per result
+	Reactions map[string][]util.Reaction // Recipes with more than two ingredients, per result
+}
+
+// Recipes turns the scraped data into the entries written to recipes.json, in Order
+func (o OrderedRevCombinations) Recipes() []RecipeJSON {
+	var out []RecipeJSON
+	for _, result := range o.Order {
+		out = append(out, RecipeJSON{
+			Result:       result,
+			Combinations: o.Map[result],
+			Asset:        o.Assets[result],
+			Tier:         o.Tiers[result],
+			Reactions:    o.Reactions[result],
+		})
+	}
+	return out
 }
 
 // To preserve insertion order in maps
@@ -39,7 +68,7 @@ func Scraper(
 	tierMap map[string]int,
 	revCombinations map[string][]util.Pair,
 	saveToFile bool,
-) (OrderedCombinations, OrderedRevCombinations) {
+) (OrderedCombinations, OrderedRevCombinations, error) {
 	// Create ordered structures
 	orderedCombinations := OrderedCombinations{
 		Map:   combinations,
@@ -47,34 +76,28 @@ func Scraper(
 	}
 
 	orderedRevCombinations := OrderedRevCombinations{
-		Map:   revCombinations,
-		Order: []string{},
+		Map:       revCombinations,
+		Order:     []string{},
+		Tiers:     tierMap,
+		Assets:    make(map[string]string),
+		Reactions: make(map[string][]util.Reaction),
 	}
 
 	// Track seen pairs and results to maintain order
 	seenPairs := make(map[util.Pair]bool)
 	seenResults := make(map[string]bool)
 
-	// Rest of your scraper code remains the same...
-	url := "https://little-alchemy.fandom.com/wiki/Elements_(Little_Alchemy_2)"
-	resp, err := http.Get(url)
+	ctx := context.Background()
+	doc, err := FetchClient.Document(ctx, ElementsURL)
 	if err != nil {
-		log.Fatal("Failed to connect to the target page:", err)
+		return orderedCombinations, orderedRevCombinations, fmt.Errorf("fetch elements page: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		log.Fatalf("HTTP Error %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		log.Fatal("Failed to parse the HTML document:", err)
-	}
-
-	assetMap := make(map[string]string)
+	assetMap := orderedRevCombinations.Assets
 	forbiddenElements := make(map[string]bool)
-	mythsAndMonstersScraper(forbiddenElements)
+	if err := mythsAndMonstersScraper(ctx, forbiddenElements); err != nil {
+		return orderedCombinations, orderedRevCombinations, fmt.Errorf("fetch myths and monsters: %w", err)
+	}
 
 	var currentTier int
 	// Parse the table and gather combinations
@@ -125,12 +148,19 @@ func Scraper(
 						parts = append(parts, txt)
 					}
 				})
-				// Ensure we have two parts for a valid combination
-				if len(parts) != 2 {
+				if len(parts) < 2 {
 					return
 				}
+				for _, part := range parts {
+					if forbiddenElements[part] {
+						return
+					}
+				}
 
-				if forbiddenElements[parts[0]] || forbiddenElements[parts[1]] {
+				// Lebih dari dua bahan gak muat di Pair, disimpen sebagai reaction
+				if len(parts) > 2 {
+					orderedRevCombinations.Reactions[result] = append(orderedRevCombinations.Reactions[result],
+						util.Reaction{Ingredients: parts, Products: []string{result}})
 					return
 				}
 
@@ -183,27 +213,9 @@ func Scraper(
 	})
 
 	if saveToFile {
-		f, err := os.Create("data/recipes.json")
-		if err != nil {
-			log.Fatal("Failed to create file:", err)
-		}
-		defer f.Close()
-
-		var out []RecipeJSON
 		// Use the ordered list of results to maintain order
-		for _, result := range orderedRevCombinations.Order {
-			out = append(out, RecipeJSON{
-				Result:       result,
-				Combinations: revCombinations[result],
-				Asset:        assetMap[result],
-				Tier:         tierMap[result],
-			})
-		}
-
-		enc := json.NewEncoder(f)
-		enc.SetIndent("", "  ")
-		if err := enc.Encode(out); err != nil {
-			log.Fatal("Failed to write JSON:", err)
+		if err := WriteRecipesFile("data/recipes.json", orderedRevCombinations.Recipes(), ElementsURL, time.Now()); err != nil {
+			return orderedCombinations, orderedRevCombinations, fmt.Errorf("write recipes: %w", err)
 		}
 		fmt.Println("Done. Saved to data/recipes.json")
 	} else {
@@ -212,30 +224,21 @@ func Scraper(
 
 	fmt.Println("Lookup available in memory.")
 
-	return orderedCombinations, orderedRevCombinations
+	return orderedCombinations, orderedRevCombinations, nil
 }
 
-func mythsAndMonstersScraper(forbid map[string]bool) {
-	url := "https://little-alchemy.fandom.com/wiki/Category:Myths_and_Monsters"
-	resp, err := http.Get(url)
+func mythsAndMonstersScraper(ctx context.Context, forbid map[string]bool) error {
+	doc, err := FetchClient.Document(ctx, MythsAndMonstersURL)
 	if err != nil {
-		log.Fatal("Failed to connect to the target page:", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		log.Fatalf("HTTP Error %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		log.Fatal("Failed to parse the HTML document:", err)
+		return err
 	}
 
 	doc.Find("ul li a.category-page__member-link").Each(func(_ int, row *goquery.Selection) {
 		element := strings.TrimSpace(row.Text())
 		forbid[element] = true
 	})
+
+	return nil
 }
 
 // getTierNumber converts a tier description string to its corresponding integer value.
@@ -255,4 +258,4 @@ func getTierNumber(currentTier string) int {
 
 	// Return -1 for invalid format
 	return -1
-}
\ No newline at end of file
+}
@@ -0,0 +1,311 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"backend/util"
+)
+
+// Snapshot layout (all integers are unsigned varints unless noted):
+//
+//	magic "LARS" + format version byte
+//	32 bytes  hash of the data the snapshot was built from (SourceHash of
+//	          recipes.json, or SourcesFingerprint of the configured sources)
+//	n         number of interned names, then n names as (length, bytes)
+//	m         number of recipe entries, then per entry: result id, tier
+//	          (zigzag varint), pair count, (first id, second id) per pair,
+//	          and since version 2 a reaction count, then per reaction the
+//	          ingredient count and ids followed by the product count and ids
+//
+// Names are interned once, pairs refer to them by index, so the whole graph
+// is a few dozen KB and decodes without any JSON parsing.
+const (
+	snapshotMagic   = "LARS"
+	snapshotVersion = 2
+)
+
+// ErrStaleSnapshot is returned when a snapshot was built from different recipe sources
+var ErrStaleSnapshot = errors.New("snapshot does not match recipe sources")
+
+// SourceHash is the hash stored in snapshots to tie them to the recipe file they came from
+func SourceHash(data []byte) [32]byte {
+	return sha256.Sum256(data)
+}
+
+// WriteSnapshot encodes recipes (v1 shape, pair order preserved) into w
+func WriteSnapshot(w io.Writer, recipes []RecipeJSON, sourceHash [32]byte) error {
+	bw := bufio.NewWriter(w)
+
+	ids := make(map[string]uint64)
+	var names []string
+	intern := func(name string) uint64 {
+		if id, exists := ids[name]; exists {
+			return id
+		}
+		id := uint64(len(names))
+		ids[name] = id
+		names = append(names, name)
+		return id
+	}
+
+	// Elemen dulu biar id-nya urut sesuai file, baru bahan yang gak kedaftar (kalo ada)
+	for _, recipe := range recipes {
+		intern(recipe.Result)
+	}
+	pairs := make([][]uint64, 0, len(recipes))
+	reactions := make([][][2][]uint64, 0, len(recipes))
+	internAll := func(names []string) []uint64 {
+		encoded := make([]uint64, 0, len(names))
+		for _, name := range names {
+			encoded = append(encoded, intern(name))
+		}
+		return encoded
+	}
+	for _, recipe := range recipes {
+		encoded := make([]uint64, 0, 2*len(recipe.Combinations))
+		for _, pair := range recipe.Combinations {
+			encoded = append(encoded, intern(pair.First), intern(pair.Second))
+		}
+		pairs = append(pairs, encoded)
+
+		var encodedReactions [][2][]uint64
+		for _, reaction := range recipe.Reactions {
+			encodedReactions = append(encodedReactions, [2][]uint64{internAll(reaction.Ingredients), internAll(reaction.Products)})
+		}
+		reactions = append(reactions, encodedReactions)
+	}
+
+	var buf [binary.MaxVarintLen64]byte
+	putUvarint := func(v uint64) {
+		n := binary.PutUvarint(buf[:], v)
+		bw.Write(buf[:n])
+	}
+
+	bw.WriteString(snapshotMagic)
+	bw.WriteByte(snapshotVersion)
+	bw.Write(sourceHash[:])
+
+	putUvarint(uint64(len(names)))
+	for _, name := range names {
+		putUvarint(uint64(len(name)))
+		bw.WriteString(name)
+	}
+
+	// Urutannya sama kayak recipes, termasuk entri dobel, biar hasil decode identik sama UnmarshalRecipes
+	putUvarint(uint64(len(recipes)))
+	for i, recipe := range recipes {
+		putUvarint(ids[recipe.Result])
+		n := binary.PutVarint(buf[:], int64(recipe.Tier))
+		bw.Write(buf[:n])
+		putUvarint(uint64(len(pairs[i]) / 2))
+		for _, id := range pairs[i] {
+			putUvarint(id)
+		}
+		putUvarint(uint64(len(reactions[i])))
+		for _, reaction := range reactions[i] {
+			for _, ids := range reaction {
+				putUvarint(uint64(len(ids)))
+				for _, id := range ids {
+					putUvarint(id)
+				}
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ReadSnapshot decodes a snapshot straight into the maps UnmarshalRecipes returns.
+// Kalo expectedHash gak nil dan beda sama hash di snapshot, hasilnya ErrStaleSnapshot.
+func ReadSnapshot(data []byte, expectedHash *[32]byte) (map[util.Pair]string, map[string][]util.Pair, map[string]int, error) {
+	recipes, err := ReadSnapshotRecipes(data, expectedHash)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rawRecipe, reversedRawRecipe, ingredientsTier := BuildRecipeMaps(recipes)
+	return rawRecipe, reversedRawRecipe, ingredientsTier, nil
+}
+
+// ReadSnapshotRecipes decodes a snapshot back into the entries it was written from
+// (tanpa Asset, snapshot cuma nyimpen grafnya)
+func ReadSnapshotRecipes(data []byte, expectedHash *[32]byte) ([]RecipeJSON, error) {
+	r := bytes.NewReader(data)
+
+	header := make([]byte, len(snapshotMagic)+1+32)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("snapshot header: %w", err)
+	}
+	if string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, fmt.Errorf("not a recipe snapshot")
+	}
+	// Versi 1 sama persis, cuma belum ada reaction
+	version := header[len(snapshotMagic)]
+	if version != 1 && version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", version)
+	}
+	if expectedHash != nil && !bytes.Equal(header[len(snapshotMagic)+1:], expectedHash[:]) {
+		return nil, ErrStaleSnapshot
+	}
+
+	var err error
+	readUvarint := func() uint64 {
+		if err != nil {
+			return 0
+		}
+		var v uint64
+		v, err = binary.ReadUvarint(r)
+		return v
+	}
+
+	count := readUvarint()
+	if err == nil && count > uint64(len(data)) {
+		err = fmt.Errorf("corrupt snapshot: %d names", count)
+	}
+	names := make([]string, 0, count)
+	for i := uint64(0); i < count && err == nil; i++ {
+		length := readUvarint()
+		if err == nil && length > uint64(r.Len()) {
+			err = fmt.Errorf("corrupt snapshot: name length %d", length)
+		}
+		if err != nil {
+			break
+		}
+		name := make([]byte, length)
+		io.ReadFull(r, name)
+		names = append(names, string(name))
+	}
+
+	name := func(id uint64) string {
+		if id >= uint64(len(names)) {
+			if err == nil {
+				err = fmt.Errorf("corrupt snapshot: element id %d out of range", id)
+			}
+			return ""
+		}
+		return names[id]
+	}
+
+	readNames := func() []string {
+		count := readUvarint()
+		if err == nil && count > uint64(r.Len()) {
+			err = fmt.Errorf("corrupt snapshot: %d names in reaction", count)
+		}
+		var list []string
+		for i := uint64(0); i < count && err == nil; i++ {
+			list = append(list, name(readUvarint()))
+		}
+		return list
+	}
+
+	entries := readUvarint()
+	if err == nil && entries > uint64(r.Len()) {
+		err = fmt.Errorf("corrupt snapshot: %d entries", entries)
+	}
+	var recipes []RecipeJSON
+	if err == nil {
+		recipes = make([]RecipeJSON, 0, entries)
+	}
+	for i := uint64(0); i < entries && err == nil; i++ {
+		recipe := RecipeJSON{Result: name(readUvarint())}
+		if err == nil {
+			var tier int64
+			tier, err = binary.ReadVarint(r)
+			recipe.Tier = int(tier)
+		}
+		pairCount := readUvarint()
+		if err == nil && pairCount > uint64(r.Len()) {
+			err = fmt.Errorf("corrupt snapshot: %d pairs for %s", pairCount, recipe.Result)
+		}
+		if err == nil && pairCount > 0 {
+			recipe.Combinations = make([]util.Pair, 0, pairCount)
+		}
+		for j := uint64(0); j < pairCount && err == nil; j++ {
+			recipe.Combinations = append(recipe.Combinations, util.Pair{First: name(readUvarint()), Second: name(readUvarint())})
+		}
+		if version >= 2 {
+			reactionCount := readUvarint()
+			if err == nil && reactionCount > uint64(r.Len()) {
+				err = fmt.Errorf("corrupt snapshot: %d reactions for %s", reactionCount, recipe.Result)
+			}
+			for j := uint64(0); j < reactionCount && err == nil; j++ {
+				ingredients := readNames()
+				products := readNames()
+				recipe.Reactions = append(recipe.Reactions, util.Reaction{Ingredients: ingredients, Products: products})
+			}
+		}
+		recipes = append(recipes, recipe)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	return recipes, nil
+}
+
+// WriteSnapshotFile bikin snapshot dari file resep (v1 atau v2) ke snapshotFile
+func WriteSnapshotFile(recipeFile, snapshotFile string) error {
+	data, err := os.ReadFile(recipeFile)
+	if err != nil {
+		return err
+	}
+	recipes, _, err := DecodeRecipeJSON(data)
+	if err != nil {
+		return err
+	}
+	return SaveSnapshot(snapshotFile, recipes, SourceHash(data))
+}
+
+// SaveSnapshot nulis snapshot recipes ke file, ditandain pake sourceHash
+func SaveSnapshot(snapshotFile string, recipes []RecipeJSON, sourceHash [32]byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(snapshotFile), ".recipes-*.snap")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WriteSnapshot(tmp, recipes, sourceHash); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), snapshotFile)
+}
+
+// LoadSnapshotMatching baca snapshotFile, tapi cuma kalo dibikin dari data dengan hash sourceHash
+func LoadSnapshotMatching(snapshotFile string, sourceHash [32]byte) ([]RecipeJSON, error) {
+	snapshot, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		return nil, err
+	}
+	return ReadSnapshotRecipes(snapshot, &sourceHash)
+}
+
+// SnapshotSource sumber data dari snapshot di memori, misalnya yang di-embed ke binary
+type SnapshotSource struct {
+	Label string
+	Data  []byte
+}
+
+func (s *SnapshotSource) Name() string { return "snapshot " + s.Label }
+
+func (s *SnapshotSource) Fingerprint() ([]byte, error) { return s.Data, nil }
+
+func (s *SnapshotSource) Apply(ctx context.Context, set *RecipeSet) error {
+	recipes, err := ReadSnapshotRecipes(s.Data, nil)
+	if err != nil {
+		return err
+	}
+	set.Merge(recipes)
+	return nil
+}
@@ -0,0 +1,104 @@
+package scraper
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"backend/util"
+)
+
+// testRecipes entri yang nyobain semua bagian format: bahan yang gak kedaftar,
+// entri dobel, tier negatif, elemen tanpa pasangan, dan reaction N bahan
+func testRecipes() []RecipeJSON {
+	return []RecipeJSON{
+		{Result: "Air", Tier: 0},
+		{Result: "Fire", Tier: 0},
+		{Result: "Energy", Tier: 1, Combinations: []util.Pair{{First: "Air", Second: "Fire"}, {First: "Fire", Second: "Air"}}},
+		{Result: "Smoke", Tier: -1, Combinations: []util.Pair{{First: "Fire", Second: "Unlisted"}}},
+		{Result: "Energy", Tier: 1, Combinations: []util.Pair{{First: "Energy", Second: "Air"}}},
+		{Result: "Storm", Tier: 2, Reactions: []util.Reaction{
+			{Ingredients: []string{"Air", "Energy", "Fire"}, Products: []string{"Storm", "Smoke"}},
+			{Ingredients: []string{"Air", "Air", "Air"}},
+		}},
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recipes.snap")
+	hash := SourceHash([]byte("recipes v1"))
+	recipes := testRecipes()
+	if err := SaveSnapshot(path, recipes, hash); err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+
+	loaded, err := LoadSnapshotMatching(path, hash)
+	if err != nil {
+		t.Fatalf("LoadSnapshotMatching: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, recipes) {
+		t.Errorf("round trip changed the recipes:\n got %+v\nwant %+v", loaded, recipes)
+	}
+
+	// File sementara SaveSnapshot gak boleh ketinggalan
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("snapshot directory has %d files, want 1", len(entries))
+	}
+}
+
+func TestSnapshotMatchesRecipeMaps(t *testing.T) {
+	var buf bytes.Buffer
+	recipes := testRecipes()
+	if err := WriteSnapshot(&buf, recipes, SourceHash(nil)); err != nil {
+		t.Fatal(err)
+	}
+	combinations, revCombinations, tiers, err := ReadSnapshot(buf.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+	wantCombinations, wantRev, wantTiers := BuildRecipeMaps(recipes)
+	if !reflect.DeepEqual(combinations, wantCombinations) || !reflect.DeepEqual(revCombinations, wantRev) || !reflect.DeepEqual(tiers, wantTiers) {
+		t.Errorf("ReadSnapshot maps differ from BuildRecipeMaps")
+	}
+}
+
+func TestSnapshotFingerprintMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recipes.snap")
+	if err := SaveSnapshot(path, testRecipes(), SourceHash([]byte("old sources"))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSnapshotMatching(path, SourceHash([]byte("new sources"))); !errors.Is(err, ErrStaleSnapshot) {
+		t.Errorf("err = %v, want ErrStaleSnapshot", err)
+	}
+	if _, err := LoadSnapshotMatching(filepath.Join(t.TempDir(), "missing.snap"), SourceHash(nil)); !os.IsNotExist(err) {
+		t.Errorf("missing snapshot err = %v, want not-exist", err)
+	}
+}
+
+func TestSnapshotCorrupt(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, testRecipes(), SourceHash(nil)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// Tiap potongan yang kepotong harus error, bukan panic atau hasil setengah
+	for n := 0; n < len(data); n++ {
+		if recipes, err := ReadSnapshotRecipes(data[:n], nil); err == nil {
+			t.Fatalf("truncated to %d of %d bytes: no error, got %d entries", n, len(data), len(recipes))
+		}
+	}
+
+	bad := append([]byte("JSON"), data[4:]...)
+	if _, err := ReadSnapshotRecipes(bad, nil); err == nil {
+		t.Errorf("wrong magic: no error")
+	}
+	future := append([]byte(nil), data...)
+	future[len(snapshotMagic)] = snapshotVersion + 1
+	if _, err := ReadSnapshotRecipes(future, nil); err == nil {
+		t.Errorf("unknown version: no error")
+	}
+}
@@ -0,0 +1,549 @@
+package scraper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/util"
+)
+
+// RecipeSource satu sumber data resep. Sumber-sumber di-apply berurutan ke
+// RecipeSet yang sama, jadi sumber belakangan bisa nambah, ganti atau hapus
+// hasil sumber sebelumnya (misalnya overrides di atas hasil scrape).
+type RecipeSource interface {
+	Name() string
+	Apply(ctx context.Context, set *RecipeSet) error
+}
+
+// fingerprinter sumber yang isinya bisa di-hash tanpa di-load penuh.
+// Cuma kombinasi sumber yang semuanya fingerprinter yang bisa di-cache pake snapshot.
+type fingerprinter interface {
+	Fingerprint() ([]byte, error)
+}
+
+// RecipeSet hasil merge semua sumber, urutan elemen dipertahanin
+type RecipeSet struct {
+	order   []string
+	entries map[string]*RecipeJSON
+}
+
+func NewRecipeSet() *RecipeSet {
+	return &RecipeSet{entries: make(map[string]*RecipeJSON)}
+}
+
+// entry ambil atau bikin elemen
+func (s *RecipeSet) entry(element string) *RecipeJSON {
+	recipe, exists := s.entries[element]
+	if !exists {
+		recipe = &RecipeJSON{Result: element}
+		s.entries[element] = recipe
+		s.order = append(s.order, element)
+	}
+	return recipe
+}
+
+// Merge nambahin elemen dan kombinasi. Tier selalu diambil dari data yang baru,
+// asset cuma kalo gak kosong. Kombinasi yang udah ada gak dobel.
+func (s *RecipeSet) Merge(recipes []RecipeJSON) {
+	for _, recipe := range recipes {
+		entry := s.entry(recipe.Result)
+		entry.Tier = recipe.Tier
+		if recipe.Asset != "" {
+			entry.Asset = recipe.Asset
+		}
+		for _, pair := range recipe.Combinations {
+			if !containsPair(entry.Combinations, pair) {
+				entry.Combinations = append(entry.Combinations, pair)
+			}
+		}
+		for _, reaction := range recipe.Reactions {
+			s.AddReaction(recipe.Result, reaction)
+		}
+	}
+}
+
+// SetTier ganti tier element (elemennya dibikin kalo belum ada)
+func (s *RecipeSet) SetTier(element string, tier int) {
+	s.entry(element).Tier = tier
+}
+
+// AddCombination nambahin first+second -> result, dua arah kayak hasil scraper
+func (s *RecipeSet) AddCombination(result, first, second string) {
+	entry := s.entry(result)
+	for _, pair := range []util.Pair{{First: first, Second: second}, {First: second, Second: first}} {
+		if !containsPair(entry.Combinations, pair) {
+			entry.Combinations = append(entry.Combinations, pair)
+		}
+	}
+}
+
+// AddReaction nambahin reaction yang ngehasilin result. Kalo ternyata cuma dua
+// bahan -> result, disimpen sebagai kombinasi biasa.
+func (s *RecipeSet) AddReaction(result string, reaction util.Reaction) {
+	reaction = normalizeReaction(result, reaction)
+	if reaction.IsPair() {
+		s.AddCombination(result, reaction.Ingredients[0], reaction.Ingredients[1])
+		return
+	}
+	entry := s.entry(result)
+	key := reaction.Key()
+	for _, existing := range entry.Reactions {
+		if normalizeReaction(result, existing).Key() == key {
+			return
+		}
+	}
+	entry.Reactions = append(entry.Reactions, reaction)
+}
+
+// normalizeReaction ngisi Products reaction milik result: kosong berarti cuma
+// result, dan result selalu jadi produk pertama
+func normalizeReaction(result string, reaction util.Reaction) util.Reaction {
+	products := []string{result}
+	for _, product := range reaction.Products {
+		if product != result {
+			products = append(products, product)
+		}
+	}
+	return util.Reaction{Ingredients: append([]string(nil), reaction.Ingredients...), Products: products}
+}
+
+// RemoveCombination hapus first+second (dua arah) dari result
+func (s *RecipeSet) RemoveCombination(result, first, second string) bool {
+	entry, exists := s.entries[result]
+	if !exists {
+		return false
+	}
+	kept := entry.Combinations[:0]
+	for _, pair := range entry.Combinations {
+		if (pair.First == first && pair.Second == second) || (pair.First == second && pair.Second == first) {
+			continue
+		}
+		kept = append(kept, pair)
+	}
+	removed := len(kept) != len(entry.Combinations)
+	entry.Combinations = kept
+	return removed
+}
+
+// RemoveElement hapus elemen beserta semua kombinasi dan reaction yang pake elemen itu
+// (sebagai bahan, atau produk sampingan reaction)
+func (s *RecipeSet) RemoveElement(element string) bool {
+	if _, exists := s.entries[element]; !exists {
+		return false
+	}
+	delete(s.entries, element)
+	for i, name := range s.order {
+		if name == element {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	for _, entry := range s.entries {
+		kept := entry.Combinations[:0]
+		for _, pair := range entry.Combinations {
+			if pair.First != element && pair.Second != element {
+				kept = append(kept, pair)
+			}
+		}
+		entry.Combinations = kept
+
+		keptReactions := entry.Reactions[:0]
+		for _, reaction := range entry.Reactions {
+			if !containsString(reaction.Ingredients, element) && !containsString(reaction.Products, element) {
+				keptReactions = append(keptReactions, reaction)
+			}
+		}
+		entry.Reactions = keptReactions
+	}
+	return true
+}
+
+// Len jumlah elemen
+func (s *RecipeSet) Len() int {
+	return len(s.order)
+}
+
+// Recipes isi set dalam bentuk RecipeJSON, urut sesuai kapan elemen pertama kali muncul
+func (s *RecipeSet) Recipes() []RecipeJSON {
+	recipes := make([]RecipeJSON, 0, len(s.order))
+	for _, name := range s.order {
+		recipes = append(recipes, *s.entries[name])
+	}
+	return recipes
+}
+
+func containsPair(pairs []util.Pair, pair util.Pair) bool {
+	for _, p := range pairs {
+		if p == pair {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(items []string, item string) bool {
+	for _, it := range items {
+		if it == item {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeSources apply semua sumber berurutan ke set kosong
+func MergeSources(ctx context.Context, sources []RecipeSource) ([]RecipeJSON, error) {
+	set := NewRecipeSet()
+	for _, source := range sources {
+		if err := source.Apply(ctx, set); err != nil {
+			return nil, fmt.Errorf("%s: %w", source.Name(), err)
+		}
+	}
+	if set.Len() == 0 {
+		return nil, errors.New("recipe sources produced no elements")
+	}
+	return set.Recipes(), nil
+}
+
+// SourcesFingerprint hash gabungan semua sumber. ok false kalo ada sumber yang
+// isinya gak bisa ditentuin tanpa di-load (misalnya scrape wiki).
+func SourcesFingerprint(sources []RecipeSource) ([32]byte, bool) {
+	hash := sha256.New()
+	for _, source := range sources {
+		fp, ok := source.(fingerprinter)
+		if !ok {
+			return [32]byte{}, false
+		}
+		data, err := fp.Fingerprint()
+		if err != nil {
+			return [32]byte{}, false
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(hash, "%s\x00%x\x00", source.Name(), sum)
+	}
+	var sum [32]byte
+	copy(sum[:], hash.Sum(nil))
+	return sum, true
+}
+
+// BuildRecipeMaps bikin map yang dipake algoritma dari daftar resep
+func BuildRecipeMaps(recipes []RecipeJSON) (map[util.Pair]string, map[string][]util.Pair, map[string]int) {
+	rawRecipe := make(map[util.Pair]string)
+	reversedRawRecipe := make(map[string][]util.Pair)
+	ingredientsTier := make(map[string]int)
+
+	for _, recipe := range recipes {
+		// Add the tier information
+		ingredientsTier[recipe.Result] = recipe.Tier
+
+		// Add the combinations to rawRecipe and reversedRawRecipe
+		for _, combo := range recipe.Combinations {
+			// Set the mapping from pair to result
+			rawRecipe[combo] = recipe.Result
+
+			// Add to reversed map
+			reversedRawRecipe[recipe.Result] = append(reversedRawRecipe[recipe.Result], combo)
+		}
+	}
+
+	return rawRecipe, reversedRawRecipe, ingredientsTier
+}
+
+// BuildDataset bikin util.Dataset dari daftar resep, termasuk reaction-nya.
+// Reaction multi-produk yang kedaftar di beberapa elemen cuma dimasukin sekali.
+func BuildDataset(name string, baseElements []string, recipes []RecipeJSON) *util.Dataset {
+	rawRecipe, reversedRawRecipe, ingredientsTier := BuildRecipeMaps(recipes)
+	ds := util.NewDataset(name, baseElements, rawRecipe, reversedRawRecipe, ingredientsTier)
+
+	seen := make(map[string]bool)
+	for _, recipe := range recipes {
+		for _, reaction := range recipe.Reactions {
+			reaction = normalizeReaction(recipe.Result, reaction)
+			if key := reaction.Key(); !seen[key] {
+				seen[key] = true
+				ds.AddReaction(reaction)
+			}
+		}
+	}
+	return ds
+}
+
+// FandomSource scrape wiki fandom. Kalo SaveTo diisi, hasil scrape juga disimpen ke situ.
+type FandomSource struct {
+	SaveTo      string
+	AfterScrape func(recipes []RecipeJSON) // Dipanggil abis scrape sukses, misalnya buat download ikon
+}
+
+func (s *FandomSource) Name() string { return "fandom" }
+
+func (s *FandomSource) Apply(ctx context.Context, set *RecipeSet) error {
+	_, ordered, err := Scraper(make(map[util.Pair]string), make(map[string]int), make(map[string][]util.Pair), false)
+	if err != nil {
+		return err
+	}
+	recipes := ordered.Recipes()
+
+	if s.SaveTo != "" {
+		if err := os.MkdirAll(filepath.Dir(s.SaveTo), os.ModePerm); err != nil {
+			return err
+		}
+		if err := WriteRecipesFile(s.SaveTo, recipes, ElementsURL, time.Now()); err != nil {
+			return fmt.Errorf("write recipes: %w", err)
+		}
+	}
+	if s.AfterScrape != nil {
+		s.AfterScrape(recipes)
+	}
+
+	set.Merge(recipes)
+	return nil
+}
+
+// FileSource file lokal: JSON (format v1 atau v2) atau CSV dengan header
+// result,first,second[,tier][,asset] (satu baris per kombinasi; first/second
+// kosong berarti baris itu cuma ngedefinisiin elemen). Resep dengan lebih dari
+// dua bahan pake kolom ingredients (dipisah "+") dan produk sampingannya di
+// kolom byproducts, misalnya "Ore+Coal+Fire" -> Iron dengan byproducts "Slag".
+type FileSource struct {
+	Path     string
+	Optional bool // File yang gak ada di-skip, bukan error
+}
+
+func (s *FileSource) Name() string { return "file " + s.Path }
+
+func (s *FileSource) Fingerprint() ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) && s.Optional {
+		return []byte("missing"), nil
+	}
+	return data, err
+}
+
+func (s *FileSource) Apply(ctx context.Context, set *RecipeSet) error {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) && s.Optional {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var recipes []RecipeJSON
+	if strings.EqualFold(filepath.Ext(s.Path), ".csv") {
+		recipes, err = decodeRecipeCSV(strings.NewReader(string(data)))
+	} else {
+		recipes, _, err = DecodeRecipeJSON(data)
+	}
+	if err != nil {
+		return err
+	}
+	set.Merge(recipes)
+	return nil
+}
+
+func decodeRecipeCSV(r io.Reader) ([]RecipeJSON, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, exists := columns["result"]; !exists {
+		return nil, errors.New("csv header must have a result column")
+	}
+	field := func(record []string, name string) string {
+		if i, exists := columns[name]; exists && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	set := NewRecipeSet()
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result := field(record, "result")
+		if result == "" {
+			return nil, fmt.Errorf("csv line %d: empty result", line)
+		}
+		entry := set.entry(result)
+		if tier := field(record, "tier"); tier != "" {
+			value, err := strconv.Atoi(tier)
+			if err != nil {
+				return nil, fmt.Errorf("csv line %d: invalid tier %q", line, tier)
+			}
+			entry.Tier = value
+		}
+		if asset := field(record, "asset"); asset != "" {
+			entry.Asset = asset
+		}
+
+		first, second := field(record, "first"), field(record, "second")
+		ingredients, byproducts := splitList(field(record, "ingredients")), splitList(field(record, "byproducts"))
+		switch {
+		case len(ingredients) > 0 || len(byproducts) > 0:
+			if first != "" || second != "" {
+				return nil, fmt.Errorf("csv line %d: use either first/second or ingredients", line)
+			}
+			if len(ingredients) == 0 {
+				return nil, fmt.Errorf("csv line %d: byproducts need ingredients", line)
+			}
+			set.AddReaction(result, util.Reaction{Ingredients: ingredients, Products: append([]string{result}, byproducts...)})
+		case first == "" && second == "":
+		case first == "" || second == "":
+			return nil, fmt.Errorf("csv line %d: combination needs both first and second", line)
+		default:
+			set.AddCombination(result, first, second)
+		}
+	}
+	return set.Recipes(), nil
+}
+
+// splitList pecah "A+B+C" jadi elemen-elemennya
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, "+") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// FirstAvailable pake sumber pertama yang berhasil di-apply, sisanya cadangan
+// (misalnya recipes.json, kalo gak ada baru scrape)
+type FirstAvailable []RecipeSource
+
+func (s FirstAvailable) Name() string {
+	names := make([]string, len(s))
+	for i, source := range s {
+		names[i] = source.Name()
+	}
+	return "first of (" + strings.Join(names, ", ") + ")"
+}
+
+// Fingerprint ikut sumber pertama; kalo sumber itu gak bisa dipake, gak bisa di-cache
+func (s FirstAvailable) Fingerprint() ([]byte, error) {
+	if len(s) == 0 {
+		return nil, errors.New("no sources")
+	}
+	fp, ok := s[0].(fingerprinter)
+	if !ok {
+		return nil, errors.New("first source has no fingerprint")
+	}
+	return fp.Fingerprint()
+}
+
+func (s FirstAvailable) Apply(ctx context.Context, set *RecipeSet) error {
+	var errs []error
+	for _, source := range s {
+		// Sumber yang gagal di tengah jalan gak boleh ninggalin data setengah jadi
+		attempt := NewRecipeSet()
+		if err := source.Apply(ctx, attempt); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+			continue
+		}
+		set.Merge(attempt.Recipes())
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// SourceConfig satu entri di sources.json
+type SourceConfig struct {
+	Type     string         `json:"type"` // "fandom", "file", "overrides", atau "firstOf"
+	Path     string         `json:"path,omitempty"`
+	SaveTo   string         `json:"saveTo,omitempty"`   // fandom: simpen hasil scrape
+	Optional bool           `json:"optional,omitempty"` // file/overrides: boleh gak ada
+	Sources  []SourceConfig `json:"sources,omitempty"`  // firstOf
+}
+
+// ParseSources bikin daftar sumber dari konfigurasi
+func ParseSources(configs []SourceConfig) ([]RecipeSource, error) {
+	sources := make([]RecipeSource, 0, len(configs))
+	for i, config := range configs {
+		var source RecipeSource
+		switch config.Type {
+		case "fandom":
+			source = &FandomSource{SaveTo: config.SaveTo}
+		case "file":
+			if config.Path == "" {
+				return nil, fmt.Errorf("source %d: file source needs a path", i)
+			}
+			source = &FileSource{Path: config.Path, Optional: config.Optional}
+		case "overrides":
+			if config.Path == "" {
+				return nil, fmt.Errorf("source %d: overrides source needs a path", i)
+			}
+			source = &OverridesSource{Path: config.Path, Optional: config.Optional}
+		case "firstOf":
+			children, err := ParseSources(config.Sources)
+			if err != nil {
+				return nil, fmt.Errorf("source %d: %w", i, err)
+			}
+			if len(children) == 0 {
+				return nil, fmt.Errorf("source %d: firstOf needs at least one source", i)
+			}
+			source = FirstAvailable(children)
+		default:
+			return nil, fmt.Errorf("source %d: unknown source type %q", i, config.Type)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// LoadSourceConfig baca sources.json: {"sources": [...]} dengan urutan merge
+func LoadSourceConfig(filename string) ([]RecipeSource, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Sources []SourceConfig `json:"sources"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if len(config.Sources) == 0 {
+		return nil, fmt.Errorf("%s: no sources configured", filename)
+	}
+	return ParseSources(config.Sources)
+}
+
+// DefaultSources perilaku tanpa sources.json: recipes.json, kalo gak ada atau rusak
+// snapshot embedded (kalo ada), baru scrape; lalu overrides kalo file-nya ada
+func DefaultSources(recipeFile, overridesFile string, embedded []byte) []RecipeSource {
+	primary := FirstAvailable{&FileSource{Path: recipeFile}}
+	if len(embedded) > 0 {
+		primary = append(primary, &SnapshotSource{Label: "embedded", Data: embedded})
+	}
+	primary = append(primary, &FandomSource{SaveTo: recipeFile})
+
+	return []RecipeSource{
+		primary,
+		&OverridesSource{Path: overridesFile, Optional: true},
+	}
+}
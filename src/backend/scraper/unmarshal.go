@@ -1,7 +1,6 @@
 package scraper
 
 import (
-	"encoding/json"
 	"os"
 
 	"backend/util"
@@ -10,40 +9,25 @@ import (
 // UnmarshalRecipes loads recipe data from a JSON file and populates the specified maps
 // Returns the populated maps and any error that occurred
 func UnmarshalRecipes(filename string) (map[util.Pair]string, map[string][]util.Pair, map[string]int, error) {
-	// Initialize the maps
-	rawRecipe := make(map[util.Pair]string)
-	reversedRawRecipe := make(map[string][]util.Pair)
-	ingredientsTier := make(map[string]int)
-
-	// Read the JSON file
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	// Unmarshal the JSON data into a slice of RecipeJSON objects
-	var recipes []RecipeJSON
-	err = json.Unmarshal(data, &recipes)
+	recipes, err := LoadRecipeJSON(filename)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	// Populate the maps
-	for _, recipe := range recipes {
-		// Add the tier information
-		ingredientsTier[recipe.Result] = recipe.Tier
-
-		// Add the combinations to rawRecipe and reversedRawRecipe
-		if recipe.Combinations != nil {
-			for _, combo := range recipe.Combinations {
-				// Set the mapping from pair to result
-				rawRecipe[combo] = recipe.Result
+	rawRecipe, reversedRawRecipe, ingredientsTier := BuildRecipeMaps(recipes)
+	return rawRecipe, reversedRawRecipe, ingredientsTier, nil
+}
 
-				// Add to reversed map
-				reversedRawRecipe[recipe.Result] = append(reversedRawRecipe[recipe.Result], combo)
-			}
-		}
+// LoadRecipeJSON reads the raw entries (v1 or v2) of a recipes file without building the lookup maps,
+// so tools like datacheck can see duplicates that the maps would hide
+func LoadRecipeJSON(filename string) ([]RecipeJSON, error) {
+	// Read the JSON file
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
 	}
 
-	return rawRecipe, reversedRawRecipe, ingredientsTier, nil
-}
\ No newline at end of file
+	// Both the legacy bare array (v1) and the versioned format (v2) are accepted
+	recipes, _, err := DecodeRecipeJSON(data)
+	return recipes, err
+}
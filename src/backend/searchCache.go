@@ -0,0 +1,52 @@
+package main
+
+import (
+	"backend/util"
+	"fmt"
+	"sync"
+)
+
+// maxCachedSearches jumlah hasil pencarian terakhir yang disimpen buat /api/diff
+const maxCachedSearches = 64
+
+// cachedSearch resep-resep yang dikirim di satu respons pencarian, urut sesuai treeData
+type cachedSearch struct {
+	Target  string
+	Recipes []map[string]util.Element
+}
+
+// searchCache nyimpen hasil pencarian terakhir, yang paling lama dibuang duluan
+type searchCache struct {
+	mu      sync.Mutex
+	nextID  int
+	entries map[string]cachedSearch
+	order   []string
+}
+
+var recentSearches = &searchCache{entries: make(map[string]cachedSearch)}
+
+// Store nyimpen hasil pencarian dan ngembaliin ID-nya
+func (c *searchCache) Store(target string, recipes []map[string]util.Element) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	id := fmt.Sprintf("s%d", c.nextID)
+	c.entries[id] = cachedSearch{Target: target, Recipes: recipes}
+	c.order = append(c.order, id)
+
+	if len(c.order) > maxCachedSearches {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	return id
+}
+
+// Get ngambil hasil pencarian berdasarkan ID
+func (c *searchCache) Get(id string) (cachedSearch, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	search, exists := c.entries[id]
+	return search, exists
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"backend/util"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Nilai query trace di /api/search
+const (
+	traceInline = "1"     // Trace ikut di respons JSON
+	traceJSONL  = "jsonl" // Trace di-download sebagai JSONL, satu event per baris
+)
+
+// traceMode ngambil mode trace dan batas event dari query string.
+// Mode kosong artinya trace gak diminta.
+func traceMode(r *http.Request) (string, int, error) {
+	query := r.URL.Query()
+	mode := query.Get("trace")
+	switch mode {
+	case "", "0":
+		return "", 0, nil
+	case traceInline, traceJSONL:
+	default:
+		return "", 0, fmt.Errorf("unsupported trace mode %q", mode)
+	}
+
+	limit := util.DefaultTraceLimit
+	if raw := query.Get("traceLimit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			return "", 0, fmt.Errorf("invalid traceLimit %q", raw)
+		}
+		limit = parsed
+	}
+	return mode, limit, nil
+}
+
+// tracedSearch ngejalanin varian trace dari algoritma yang diminta (util.TraceSearcher)
+// sambil nyatet event-nya. Hasilnya cuma satu resep, bukan MultipleX, biar log-nya
+// bisa diikutin langkah demi langkah.
+func tracedSearch(w http.ResponseWriter, req SearchRequest, ds *util.Dataset, policy util.ValidityPolicy, mode string, limit int) {
+	if ds.HasReactions() || req.RankBy != "" || req.Diverse {
+		http.Error(w, "trace is not supported with reactions, rankBy or diverse", http.StatusBadRequest)
+		return
+	}
+
+	searcher, err := util.SearcherByName(req.Algoritma)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tracer, ok := searcher.(util.TraceSearcher)
+	if !ok {
+		http.Error(w, fmt.Sprintf("algorithm %q does not support trace", req.Algoritma), http.StatusBadRequest)
+		return
+	}
+
+	trace := util.NewSearchTrace(limit)
+	start := time.Now()
+	recipe, found, stats := tracer.SearchTrace(req.NamaResep, ds, policy, util.SearchOptions{BeamWidth: req.BeamWidth}, trace)
+	elapsed := time.Since(start)
+
+	if mode == traceJSONL {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "trace-"+req.NamaResep+".jsonl"))
+		if err := trace.WriteJSONL(w); err != nil {
+			log.Printf("Error writing trace: %v", err)
+		}
+		return
+	}
+
+	response := TreeResponse{
+		TreeData:    []*util.Node{},
+		TimeTaken:   elapsed.String(),
+		NodeVisited: len(recipe),
+		Stats:       stats,
+		Trace:       trace,
+	}
+	if found && len(recipe) > 0 {
+		tree, nodeVisited := util.BuildTree(req.NamaResep, recipe)
+		response.TreeData = append(response.TreeData, tree)
+		response.NodeVisited = nodeVisited
+		response.SearchID = recentSearches.Store(req.NamaResep, []map[string]util.Element{recipe})
+	}
+	req.writeTrees(w, ds, policy, response)
+}
@@ -9,14 +9,14 @@ package util
 // sampai nemuin target (atau habis opsi). Hasilnya adalah map
 // dari produk ke Element (siapa Source & Partner yang ngasilin itu),
 // jadi kita bisa nyusun lagi jalur resepnya nanti.
-func Legacy_ShortestBfs(target string, combinations map[Pair]string) map[string]Element {
+func Legacy_ShortestBfs(target string, ds *Dataset) map[string]Element {
 	// 1) Siapin queue yang berisi elemen dasar
-	queue := make([]string, len(BaseElements))
-	copy(queue, BaseElements)
+	queue := make([]string, len(ds.BaseElements))
+	copy(queue, ds.BaseElements)
 
 	// 2) seen = set buat tandain elemen yang udah kita lihat
-	seen := make(map[string]bool, len(BaseElements))
-	for _, b := range BaseElements {
+	seen := make(map[string]bool, len(ds.BaseElements))
+	for _, b := range ds.BaseElements {
 		seen[b] = true
 	}
 
@@ -30,15 +30,15 @@ func Legacy_ShortestBfs(target string, combinations map[Pair]string) map[string]
 
 		// Kalo current udah sama dengan target, kita berhenti nyari
 		if current == target {
-				break
+			break
 		}
 
 		// 5) Untuk tiap bahan "partner" yang udah kita lihat,
-		//    coba gabungin current + partner, pake combinations[pair] -> produk
+		//    coba gabungin current + partner, pake ds.Combinations[pair] -> produk
 		for partner := range seen {
 			// Cari produk yang bisa dibikin dengan Pair{A: current, B: partner}
 			pair := Pair{First: current, Second: partner}
-			if product, exists := combinations[pair]; exists {
+			if product, exists := ds.Combinations[pair]; exists {
 				// 6) Kalo produk baru (belum pernah dilihat), tandai dan masukin queue
 				if !seen[product] {
 					seen[product] = true
@@ -54,4 +54,4 @@ func Legacy_ShortestBfs(target string, combinations map[Pair]string) map[string]
 	// 7) Kembalikan map prev. Dari sini kita bisa bikin jalur resep:
 	//    mulai dari target, lihat prev[target], terus lihat prev[Source], dst.
 	return prev
-}
\ No newline at end of file
+}
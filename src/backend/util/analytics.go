@@ -0,0 +1,412 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ElementAnalytics angka-angka graf resep buat satu elemen menurut satu policy
+type ElementAnalytics struct {
+	Element       string  `json:"element"`
+	Tier          int     `json:"tier"`
+	IsBase        bool    `json:"isBase"`
+	Reachable     bool    `json:"reachable"`
+	UsedIn        int     `json:"usedIn"`      // Resep (pasangan + produk) valid di policy yang make elemen ini sebagai bahan
+	Products      int     `json:"products"`    // Elemen berbeda yang bisa dibikin dari elemen ini di policy
+	Recipes       int     `json:"recipes"`     // Pasangan valid yang ngasilin elemen ini
+	Betweenness   float64 `json:"betweenness"` // Betweenness di DAG bahan -> produk, dinormalisasi ke 0..1
+	MinDepth      int     `json:"minDepth"`
+	MinSteps      int     `json:"minSteps"`      // Langkah minimal, sama kayak minSteps di katalog default
+	MinStepsExact bool    `json:"minStepsExact"` // false: budget habis, MinSteps resep terbaik yang ketemu
+	Difficulty    float64 `json:"difficulty"`    // MinSteps dibanding elemen tersulit, 0..100
+}
+
+// GraphAnalytics analytics semua elemen dataset, dihitung sekali per policy
+type GraphAnalytics struct {
+	Dataset  string
+	Policy   string
+	Elements map[string]*ElementAnalytics
+}
+
+// Metrik yang bisa dipake Leaderboard
+const (
+	LeaderboardDifficulty  = "difficulty"
+	LeaderboardUsage       = "usage"
+	LeaderboardBetweenness = "betweenness"
+)
+
+// Analytics ngitung (atau ngambil dari cache) analytics semua elemen ds menurut
+// policy. Graf-nya diambil dari RevCombinations: tiap pasangan valid yang bahannya
+// punya level derivasi lebih kecil dari produknya jadi sisi bahan -> produk, jadi
+// grafnya pasti DAG di policy apa pun.
+func Analytics(ds *Dataset, policy ValidityPolicy) *GraphAnalytics {
+	if analytics, exists := ds.analytics.Load(policy.Name()); exists {
+		return analytics.(*GraphAnalytics)
+	}
+
+	levels := DerivationLevels(ds, policy)
+	analytics := &GraphAnalytics{Dataset: ds.Name, Policy: policy.Name(), Elements: make(map[string]*ElementAnalytics)}
+	entry := func(elem string) *ElementAnalytics {
+		if a, exists := analytics.Elements[elem]; exists {
+			return a
+		}
+		level, reachable := levels[elem]
+		a := &ElementAnalytics{Element: elem, Tier: ds.TierMap[elem], IsBase: ds.IsBase(elem), Reachable: reachable, MinDepth: level}
+		analytics.Elements[elem] = a
+		return a
+	}
+	for elem := range ds.TierMap {
+		entry(elem)
+	}
+	for _, base := range ds.BaseElements {
+		entry(base)
+	}
+
+	// Pemakaian sebagai bahan, cuma dari resep yang diizinin policy
+	products := make(map[string]map[string]bool)
+	for product, pairs := range ds.RevCombinations {
+		entry(product)
+		for _, pair := range uniquePairs(filterValidPairs(pairs, product, ds.TierMap, policy)) {
+			for _, ingredient := range []string{pair.First, pair.Second} {
+				entry(ingredient).UsedIn++
+				if products[ingredient] == nil {
+					products[ingredient] = make(map[string]bool)
+				}
+				products[ingredient][product] = true
+				if pair.Second == pair.First {
+					break
+				}
+			}
+		}
+	}
+	for ingredient, made := range products {
+		analytics.Elements[ingredient].Products = len(made)
+	}
+
+	// DAG bahan -> produk dari pasangan valid yang level-nya turun
+	edges := make(map[string][]string)
+	for product, pairs := range ds.RevCombinations {
+		if ds.IsBase(product) {
+			continue
+		}
+		valid := filterByLevel(uniquePairs(filterValidPairs(pairs, product, ds.TierMap, policy)), product, levels)
+		analytics.Elements[product].Recipes = len(valid)
+		seen := make(map[string]bool)
+		for _, pair := range valid {
+			for _, ingredient := range []string{pair.First, pair.Second} {
+				if !seen[ingredient] {
+					seen[ingredient] = true
+					edges[ingredient] = append(edges[ingredient], product)
+				}
+			}
+		}
+	}
+	for _, products := range edges {
+		sort.Strings(products) // Urutan map gak tetap, hasil float-nya harus tetap
+	}
+	for elem, score := range betweenness(edges) {
+		analytics.Elements[elem].Betweenness = score
+	}
+
+	// Langkah minimal (sama kayak katalog default) dan tingkat kesulitan
+	bounds := newStepsBounds(ds, policy, levels)
+	hardest := 0
+	for elem := range levels {
+		a := analytics.Elements[elem]
+		a.MinSteps, a.MinStepsExact = bounds.minimumSteps(elem, ds, policy, DefaultStepsBudget)
+		hardest = max(hardest, a.MinSteps)
+	}
+	if hardest > 0 {
+		for _, a := range analytics.Elements {
+			if a.Reachable {
+				a.Difficulty = math.Round(1000*float64(a.MinSteps)/float64(hardest)) / 10
+			}
+		}
+	}
+
+	actual, _ := ds.analytics.LoadOrStore(policy.Name(), analytics)
+	return actual.(*GraphAnalytics)
+}
+
+// Leaderboard limit elemen teratas menurut metric (difficulty, usage, betweenness).
+// Elemen dasar dan elemen yang gak bisa dibikin gak ikut. limit <= 0 berarti semua.
+func (a *GraphAnalytics) Leaderboard(metric string, limit int) ([]ElementAnalytics, error) {
+	var value func(e *ElementAnalytics) float64
+	switch metric {
+	case "", LeaderboardDifficulty:
+		value = func(e *ElementAnalytics) float64 { return e.Difficulty }
+	case LeaderboardUsage:
+		value = func(e *ElementAnalytics) float64 { return float64(e.UsedIn) }
+	case LeaderboardBetweenness:
+		value = func(e *ElementAnalytics) float64 { return e.Betweenness }
+	default:
+		return nil, fmt.Errorf("unsupported leaderboard metric %q", metric)
+	}
+
+	board := []ElementAnalytics{}
+	for _, e := range a.Elements {
+		if e.Reachable && !e.IsBase {
+			board = append(board, *e)
+		}
+	}
+	sort.Slice(board, func(i, j int) bool {
+		vi, vj := value(&board[i]), value(&board[j])
+		if vi != vj {
+			return vi > vj
+		}
+		return board[i].Element < board[j].Element
+	})
+	if limit > 0 && len(board) > limit {
+		board = board[:limit]
+	}
+	return board, nil
+}
+
+// betweenness betweenness centrality graf berarah tak berbobot (algoritma Brandes),
+// dinormalisasi pake (n-1)(n-2)
+func betweenness(edges map[string][]string) map[string]float64 {
+	nodes := make(map[string]bool)
+	for from, targets := range edges {
+		nodes[from] = true
+		for _, to := range targets {
+			nodes[to] = true
+		}
+	}
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	scores := make(map[string]float64, len(names))
+	for _, source := range names {
+		var order []string
+		predecessors := make(map[string][]string)
+		paths := map[string]float64{source: 1}
+		distance := map[string]int{source: 0}
+
+		queue := []string{source}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			order = append(order, current)
+			for _, next := range edges[current] {
+				if _, seen := distance[next]; !seen {
+					distance[next] = distance[current] + 1
+					queue = append(queue, next)
+				}
+				if distance[next] == distance[current]+1 {
+					paths[next] += paths[current]
+					predecessors[next] = append(predecessors[next], current)
+				}
+			}
+		}
+
+		dependency := make(map[string]float64)
+		for i := len(order) - 1; i >= 0; i-- {
+			w := order[i]
+			for _, v := range predecessors[w] {
+				dependency[v] += paths[v] / paths[w] * (1 + dependency[w])
+			}
+			if w != source {
+				scores[w] += dependency[w]
+			}
+		}
+	}
+
+	if n := len(names); n > 2 {
+		scale := 1 / float64((n-1)*(n-2))
+		for name := range scores {
+			scores[name] *= scale
+		}
+	}
+	return scores
+}
+
+// DefaultStepsBudget batas ekspansi minimumSteps per elemen, dipake Analytics dan
+// default BuildCatalog biar minSteps keduanya sama. Tiap 1000 ekspansi kira-kira
+// 10ms per elemen, jadi Analytics (dihitung waktu request) gak bisa gede-gede.
+const DefaultStepsBudget = 100
+
+// stepsBounds data buat minimumSteps yang cukup dihitung sekali per dataset dan policy
+type stepsBounds struct {
+	levels   map[string]int // DerivationLevels, batas bawah langkah
+	lower    map[string]int // minimalScores buat heuristik kBestRecipes
+	estimate map[string]int // estimateMinSteps, batas atas kalo budget habis
+}
+
+func newStepsBounds(ds *Dataset, policy ValidityPolicy, levels map[string]int) stepsBounds {
+	return stepsBounds{
+		levels:   levels,
+		lower:    minimalScores(ds, policy, MetricSteps),
+		estimate: estimateMinSteps(ds, policy, levels),
+	}
+}
+
+// minimumSteps langkah minimal elem (yang bisa dibikin), dipake katalog dan analytics
+// biar angkanya sama. Dicari pake kBestRecipes dengan batas ekspansi budget; kalo
+// budget habis hasilnya yang terkecil dari estimateMinSteps dan resep ShortestBidirectional,
+// dan cuma pasti minimal kalo sama dengan kedalaman minimal karena tiap level butuh
+// minimal satu langkah.
+func (b stepsBounds) minimumSteps(elem string, ds *Dataset, policy ValidityPolicy, budget int) (steps int, exact bool) {
+	best := kBestRecipes(elem, ds, policy, 1, MetricSteps, budget, b.lower)
+	if len(best.Recipes) > 0 {
+		return best.Recipes[0].Score, true
+	}
+	steps = min(b.estimate[elem], len(ShortestBidirectional(elem, ds, policy)))
+	return steps, steps == b.levels[elem]
+}
+
+// minStepsCandidates jumlah kandidat resep per elemen yang disimpen estimateMinSteps
+const minStepsCandidates = 4
+
+// estimateMinSteps perkiraan langkah minimal tiap elemen yang bisa dibikin.
+// Elemen diproses urut level; tiap elemen nyimpen beberapa kandidat resep dengan
+// langkah paling sedikit, hasil gabungan kandidat bahan-bahannya (langkah yang sama
+// dihitung sekali). Kandidatnya resep beneran, jadi hasilnya gak pernah lebih kecil
+// dari minimal sebenarnya, tapi bisa lebih besar kalo resep minimalnya butuh
+// kandidat bahan yang udah kebuang.
+func estimateMinSteps(ds *Dataset, policy ValidityPolicy, levels map[string]int) map[string]int {
+	elements := make([]string, 0, len(levels))
+	for elem := range levels {
+		elements = append(elements, elem)
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		if levels[elements[i]] != levels[elements[j]] {
+			return levels[elements[i]] < levels[elements[j]]
+		}
+		return elements[i] < elements[j]
+	})
+
+	made := make(map[string][]map[string]bool, len(elements)) // Elemen -> kandidat langkah resepnya
+	steps := make(map[string]int, len(elements))
+	for _, elem := range elements {
+		if ds.IsBase(elem) {
+			made[elem] = []map[string]bool{{}}
+			steps[elem] = 0
+			continue
+		}
+
+		var candidates []map[string]bool
+		seen := make(map[string]bool)
+		pairs := filterByLevel(uniquePairs(filterValidPairs(ds.RevCombinations[elem], elem, ds.TierMap, policy)), elem, levels)
+		for _, pair := range pairs {
+			for _, first := range made[pair.First] {
+				for _, second := range made[pair.Second] {
+					union := map[string]bool{elem: true}
+					for step := range first {
+						union[step] = true
+					}
+					for step := range second {
+						union[step] = true
+					}
+					if key := stepsKey(union); !seen[key] {
+						seen[key] = true
+						candidates = append(candidates, union)
+					}
+				}
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool { return len(candidates[i]) < len(candidates[j]) })
+		if len(candidates) > minStepsCandidates {
+			candidates = candidates[:minStepsCandidates]
+		}
+		made[elem] = candidates
+		steps[elem] = len(candidates[0])
+	}
+	return steps
+}
+
+// stepsKey kunci kandidat resep buat ngebuang kandidat kembar
+func stepsKey(steps map[string]bool) string {
+	names := make([]string, 0, len(steps))
+	for name := range steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, "|")
+}
+
+// CriticalElements elemen (termasuk elemen dasar) yang ada di setiap resep valid
+// target: kalo elemen itu dilarang, target jadi mustahil dibikin. Urut level, terus nama.
+func CriticalElements(target string, ds *Dataset, policy ValidityPolicy) []string {
+	critical := []string{}
+	levels := DerivationLevels(ds, policy)
+	if _, reachable := levels[target]; !reachable || ds.IsBase(target) {
+		return critical
+	}
+
+	// Elemen yang ada di semua resep pasti ada juga di satu resep valid mana pun.
+	// Resepnya harus lengkap (ShortestBfs kadang ngelewatin bahan), jadi pake Bidirectional.
+	recipe := ShortestBidirectional(target, ds, policy)
+	candidates := make(map[string]bool)
+	for elem, sources := range recipe {
+		candidates[elem] = true
+		candidates[sources.Source] = true
+		candidates[sources.Partner] = true
+	}
+	delete(candidates, "")
+	delete(candidates, target)
+
+	// Pasangan valid elemen yang bisa dibikin, urut level biar relaksasinya cepet selesai
+	elements := make([]string, 0, len(levels))
+	for elem, level := range levels {
+		if level > 0 {
+			elements = append(elements, elem)
+		}
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		if levels[elements[i]] != levels[elements[j]] {
+			return levels[elements[i]] < levels[elements[j]]
+		}
+		return elements[i] < elements[j]
+	})
+	pairs := make([][]Pair, len(elements))
+	for i, elem := range elements {
+		pairs[i] = uniquePairs(filterValidPairs(ds.RevCombinations[elem], elem, ds.TierMap, policy))
+	}
+
+	for candidate := range candidates {
+		if !derivableWithout(target, candidate, ds, elements, pairs) {
+			critical = append(critical, candidate)
+		}
+	}
+	sort.Slice(critical, func(i, j int) bool {
+		if levels[critical[i]] != levels[critical[j]] {
+			return levels[critical[i]] < levels[critical[j]]
+		}
+		return critical[i] < critical[j]
+	})
+	return critical
+}
+
+// derivableWithout ngecek apakah target masih bisa dibikin kalo excluded gak boleh
+// dipake. elements semua elemen non-dasar yang bisa dibikin, pairs pasangan validnya.
+func derivableWithout(target, excluded string, ds *Dataset, elements []string, pairs [][]Pair) bool {
+	known := make(map[string]bool, len(elements))
+	for _, base := range ds.BaseElements {
+		if base != excluded {
+			known[base] = true
+		}
+	}
+
+	// Relaksasi sampai gak ada elemen baru, sama kayak minimalScores
+	for changed := true; changed && !known[target]; {
+		changed = false
+		for i, elem := range elements {
+			if known[elem] || elem == excluded {
+				continue
+			}
+			for _, pair := range pairs[i] {
+				if known[pair.First] && known[pair.Second] {
+					known[elem] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return known[target]
+}
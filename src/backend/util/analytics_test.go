@@ -0,0 +1,131 @@
+package util_test
+
+import (
+	"slices"
+	"sort"
+	"testing"
+
+	"backend/util"
+)
+
+// bruteForceCritical nyoba ngelarang tiap elemen yang bisa dibikin satu-satu,
+// terus ngecek target masih bisa dibikin atau nggak
+func bruteForceCritical(ds *util.Dataset, policy util.ValidityPolicy, target string) []string {
+	levels := util.DerivationLevels(ds, policy)
+	critical := []string{}
+	if _, reachable := levels[target]; !reachable || ds.IsBase(target) {
+		return critical
+	}
+	for excluded := range levels {
+		if excluded != target && !derivableExcluding(ds, policy, target, excluded) {
+			critical = append(critical, excluded)
+		}
+	}
+	sort.Strings(critical)
+	return critical
+}
+
+func derivableExcluding(ds *util.Dataset, policy util.ValidityPolicy, target, excluded string) bool {
+	known := make(map[string]bool)
+	for _, base := range ds.BaseElements {
+		if base != excluded {
+			known[base] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for elem, pairs := range ds.RevCombinations {
+			if known[elem] || elem == excluded {
+				continue
+			}
+			for _, pair := range pairs {
+				if known[pair.First] && known[pair.Second] && policy.Allows(elem, pair, ds.TierMap) {
+					known[elem] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return known[target]
+}
+
+// checkCritical nyocokin CriticalElements sama brute force, urutannya gak dicek
+func checkCritical(t *testing.T, ds *util.Dataset, policy util.ValidityPolicy, target string) []string {
+	t.Helper()
+	got := slices.Clone(util.CriticalElements(target, ds, policy))
+	sort.Strings(got)
+	if want := bruteForceCritical(ds, policy, target); !slices.Equal(got, want) {
+		t.Errorf("%s (%s): critical %v, want %v", target, policy.Name(), got, want)
+	}
+	return got
+}
+
+func TestCriticalElementsFixture(t *testing.T) {
+	ds := fixtureDataset()
+	for _, policy := range testPolicies {
+		for _, target := range sortedElements(ds) {
+			checkCritical(t, ds, policy, target)
+		}
+	}
+}
+
+func TestCriticalElementsRealData(t *testing.T) {
+	ds := loadRealDataset(t)
+	policy := util.StrictTierPolicy{}
+	for _, target := range []string{"Vampire", "Blood bag", "Human", "Life", "Lasso", "Time"} {
+		got := checkCritical(t, ds, policy, target)
+		if (target == "Vampire" || target == "Blood bag") && !slices.Contains(got, "Blood") {
+			t.Errorf("%s: critical %v is missing Blood", target, got)
+		}
+	}
+}
+
+// UsedIn cuma ngitung resep (pasangan + produk) yang diizinin policy
+func TestAnalyticsUsedInFixture(t *testing.T) {
+	ds := fixtureDataset()
+	for _, policy := range testPolicies {
+		want := make(map[string]int)
+		for product, pairs := range ds.RevCombinations {
+			seen := make(map[util.Pair]bool)
+			for _, pair := range pairs {
+				if pair.First > pair.Second {
+					pair = util.Pair{First: pair.Second, Second: pair.First}
+				}
+				if seen[pair] || !policy.Allows(product, pair, ds.TierMap) {
+					continue
+				}
+				seen[pair] = true
+				want[pair.First]++
+				if pair.Second != pair.First {
+					want[pair.Second]++
+				}
+			}
+		}
+		for _, elem := range sortedElements(ds) {
+			if got := util.Analytics(ds, policy).Elements[elem].UsedIn; got != want[elem] {
+				t.Errorf("%s (%s): usedIn %d, want %d", elem, policy.Name(), got, want[elem])
+			}
+		}
+	}
+}
+
+// MinSteps analytics sama dengan katalog yang budget-nya default
+func TestAnalyticsMatchesCatalog(t *testing.T) {
+	for _, ds := range []*util.Dataset{fixtureDataset(), misleadingTierDataset()} {
+		for _, policy := range testPolicies {
+			catalog, err := util.BuildCatalog(ds, policy, util.CatalogOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			analytics := util.Analytics(ds, policy)
+			for _, entry := range catalog.Elements {
+				a := analytics.Elements[entry.Element]
+				if entry.Reachable && (a.MinSteps != entry.MinSteps || a.MinStepsExact != entry.MinStepsExact) {
+					t.Errorf("%s %s (%s): analytics minSteps %d (exact %v), catalog %d (exact %v)",
+						ds.Name, entry.Element, policy.Name(), a.MinSteps, a.MinStepsExact, entry.MinSteps, entry.MinStepsExact)
+				}
+			}
+		}
+	}
+}
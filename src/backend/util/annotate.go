@@ -0,0 +1,80 @@
+package util
+
+// AnnotateTree ngisi Meta tiap node di tree (tier, ikon, kedalaman, jumlah langkah
+// subtree, elemen dasar atau bukan, dan jumlah resep valid). Node yang dipake
+// bareng beberapa parent cuma punya satu Meta, jadi Depth-nya jarak terpendek dari root.
+// asset boleh nil.
+func AnnotateTree(root *Node, ds *Dataset, policy ValidityPolicy, asset func(element string) string) {
+	if root == nil {
+		return
+	}
+
+	// Kedalaman pake BFS biar tiap node dapet jarak terpendeknya
+	depths := map[*Node]int{root: 0}
+	queue := []*Node{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range current.Children {
+			if _, seen := depths[child]; !seen {
+				depths[child] = depths[current] + 1
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	steps := make(map[*Node]map[string]bool)
+	for node, depth := range depths {
+		meta := &NodeMeta{
+			Tier:         ds.TierMap[node.Name],
+			Depth:        depth,
+			Steps:        len(subtreeSteps(node, steps)),
+			IsBase:       ds.IsBase(node.Name),
+			Alternatives: countRecipes(node.Name, ds, policy),
+		}
+		if asset != nil {
+			meta.Asset = asset(node.Name)
+		}
+		node.Meta = meta
+	}
+}
+
+// AnnotateTrees AnnotateTree buat tiap tree
+func AnnotateTrees(trees []*Node, ds *Dataset, policy ValidityPolicy, asset func(element string) string) {
+	for _, tree := range trees {
+		AnnotateTree(tree, ds, policy, asset)
+	}
+}
+
+// subtreeSteps elemen hasil kombinasi di subtree node (node tanpa anak gak dihitung).
+// Hasilnya di-memo per node karena subtree bisa dipake bareng.
+func subtreeSteps(node *Node, memo map[*Node]map[string]bool) map[string]bool {
+	if made, exists := memo[node]; exists {
+		return made
+	}
+	made := make(map[string]bool)
+	if len(node.Children) > 0 {
+		made[node.Name] = true
+	}
+	memo[node] = made
+	for _, child := range node.Children {
+		for elem := range subtreeSteps(child, memo) {
+			made[elem] = true
+		}
+	}
+	return made
+}
+
+// countRecipes jumlah resep yang diizinin policy buat element: pair unik hasil
+// filterValidPairs ditambah reaction non-pair
+func countRecipes(element string, ds *Dataset, policy ValidityPolicy) int {
+	count := len(uniquePairs(filterValidPairs(ds.RevCombinations[element], element, ds.TierMap, policy)))
+	if ds.HasReactions() {
+		for _, r := range ds.ReactionsFor(element) {
+			if !r.IsPair() && allowsReaction(policy, element, r, ds.TierMap) {
+				count++
+			}
+		}
+	}
+	return count
+}
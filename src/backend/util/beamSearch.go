@@ -0,0 +1,136 @@
+package util
+
+import (
+	"slices"
+	"sort"
+)
+
+// DefaultBeamWidth jumlah resep parsial yang dipertahanin BeamSearch tiap langkah
+const DefaultBeamWidth = 16
+
+// beamState resep parsial di beam, bentuknya sama kayak kBestState
+type beamState struct {
+	recipe   *RecipeChain
+	open     []string
+	assigned int
+	score    int // assigned + perkiraan langkah buat elemen terbuka
+	seq      int
+}
+
+// BeamSearch nyari satu resep dengan cepat tanpa jaminan paling pendek. Tiap
+// langkah semua resep parsial di beam ngebuka satu elemen terbukanya, terus cuma
+// width kandidat dengan skor terkecil (jumlah langkah + perkiraan dari tier) yang
+// dilanjutin. width <= 0 pake DefaultBeamWidth; makin lebar makin deket ke optimal.
+func BeamSearch(target string, ds *Dataset, policy ValidityPolicy, width int) map[string]Element {
+	return beamSearch(target, ds, policy, width, nil)
+}
+
+// BeamSearchWithStats BeamSearch beserta statistik pencariannya
+func BeamSearchWithStats(target string, ds *Dataset, policy ValidityPolicy, width int) (map[string]Element, SearchStats) {
+	c := newSearchCounters()
+	recipe := beamSearch(target, ds, policy, width, c)
+	return recipe, c.finish()
+}
+
+func beamSearch(target string, ds *Dataset, policy ValidityPolicy, width int, c *searchCounters) map[string]Element {
+	if width <= 0 {
+		width = DefaultBeamWidth
+	}
+	levels := DerivationLevels(ds, policy)
+	if _, reachable := levels[target]; !reachable || ds.IsBase(target) {
+		return make(map[string]Element)
+	}
+
+	// Bahan wajib bisa dibikin. Policy yang bisa siklik diarahin pake level
+	// derivasi kayak ShortestDfs, jadi beam gak pernah isi resep yang muter.
+	acyclic := isAcyclicByConstruction(policy)
+	pairCache := make(map[string][]Pair)
+	validPairsOf := func(elem string) []Pair {
+		if pairs, exists := pairCache[elem]; exists {
+			return pairs
+		}
+		pairs := uniquePairs(filterValidPairs(ds.RevCombinations[elem], elem, ds.TierMap, policy))
+		c.recordRejected(elem, ds.RevCombinations[elem], ds, policy)
+		if acyclic {
+			pairs = slices.DeleteFunc(pairs, func(pair Pair) bool {
+				_, firstOk := levels[pair.First]
+				_, secondOk := levels[pair.Second]
+				return !firstOk || !secondOk
+			})
+		} else {
+			pairs = filterByLevel(pairs, elem, levels)
+		}
+		pairCache[elem] = pairs
+		return pairs
+	}
+	heuristic := tierHeuristic(ds)
+
+	seq := 0
+	beam := []*beamState{{recipe: NewRecipeChain(nil), open: []string{target}, score: heuristic[target]}}
+	c.record(TraceEvent{Kind: TraceEnqueue, Element: target})
+	for len(beam) > 0 {
+		c.observeFrontier(len(beam))
+		// Beam diurutin dari skor terkecil; resep lengkap di depan berarti selesai
+		if len(beam[0].open) == 0 {
+			return beam[0].recipe.Materialize()
+		}
+
+		var candidates []*beamState
+		for _, state := range beam {
+			if len(state.open) == 0 {
+				candidates = append(candidates, state)
+				continue
+			}
+			c.expand()
+
+			elem := state.open[len(state.open)-1]
+			rest := state.open[:len(state.open)-1]
+			c.record(TraceEvent{Kind: TraceDequeue, Element: elem})
+			for _, pair := range validPairsOf(elem) {
+				c.examine(1)
+				if pair.First == elem || pair.Second == elem {
+					continue
+				}
+				c.recordPair(elem, pair, elem, true, "")
+
+				open := append([]string(nil), rest...)
+				for _, ingredient := range []string{pair.First, pair.Second} {
+					if ds.IsBase(ingredient) || slices.Contains(open, ingredient) {
+						continue
+					}
+					if _, assigned := state.recipe.Get(ingredient); assigned {
+						continue
+					}
+					open = append(open, ingredient)
+					c.record(TraceEvent{Kind: TraceEnqueue, Element: ingredient})
+				}
+
+				seq++
+				child := &beamState{
+					recipe:   state.recipe.Derive(map[string]Element{elem: {Source: pair.First, Partner: pair.Second}}),
+					open:     open,
+					assigned: state.assigned + 1,
+					seq:      seq,
+				}
+				child.score = child.assigned
+				for _, pending := range open {
+					child.score += heuristic[pending]
+				}
+				candidates = append(candidates, child)
+			}
+		}
+
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].score != candidates[j].score {
+				return candidates[i].score < candidates[j].score
+			}
+			return candidates[i].seq < candidates[j].seq
+		})
+		if len(candidates) > width {
+			candidates = candidates[:width]
+		}
+		beam = candidates
+	}
+
+	return make(map[string]Element)
+}
@@ -0,0 +1,211 @@
+package util
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default BuildCatalog
+const (
+	DefaultCatalogRecipeCap   = 100
+	DefaultCatalogStepsBudget = DefaultStepsBudget
+)
+
+// Catalog ringkasan semua elemen di satu dataset, dihitung sekali di luar server
+type Catalog struct {
+	Dataset     string         `json:"dataset"`
+	Policy      string         `json:"policy"`
+	RecipeCap   int            `json:"recipeCap"`
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Elements    []CatalogEntry `json:"elements"` // Urut tier, terus nama
+}
+
+// CatalogEntry ringkasan satu elemen. Elemen yang gak bisa dibikin di policy ini
+// cuma keisi Element, Tier dan Reachable.
+type CatalogEntry struct {
+	Element           string        `json:"element"`
+	Tier              int           `json:"tier"`
+	IsBase            bool          `json:"isBase"`
+	Reachable         bool          `json:"reachable"`
+	MinDepth          int           `json:"minDepth"`
+	MinSteps          int           `json:"minSteps"`
+	MinStepsExact     bool          `json:"minStepsExact"`     // false: budget habis, MinSteps resep terbaik yang ketemu
+	RecipeCount       int           `json:"recipeCount"`       // Resep berbeda, paling banyak RecipeCap
+	RecipeCountCapped bool          `json:"recipeCountCapped"` // Resepnya lebih banyak dari RecipeCount
+	ShortestRecipe    []CatalogStep `json:"shortestRecipe"`    // Bahan selalu dibikin sebelum dipake
+}
+
+// CatalogStep satu langkah kombinasi di resep katalog
+type CatalogStep struct {
+	Element string `json:"element"`
+	Source  string `json:"source"`
+	Partner string `json:"partner"`
+}
+
+// CatalogOptions batas kerja BuildCatalog, nilai <= 0 pake default
+type CatalogOptions struct {
+	RecipeCap   int // Batas resep yang dihitung per elemen
+	StepsBudget int // Batas ekspansi minimumSteps per elemen
+	Workers     int // Elemen yang dihitung barengan
+}
+
+// BuildCatalog ngitung resep terpendek (ShortestBidirectional), jumlah resep berbeda
+// (MultipleDfs sampai RecipeCap), kedalaman minimal dan langkah minimal tiap elemen.
+// Langkah minimal dicari pake minimumSteps, jadi sama kayak Analytics selama
+// StepsBudget-nya default. Error kalo ada resep terpendek yang kedalamannya gak
+// sama dengan MinDepth.
+func BuildCatalog(ds *Dataset, policy ValidityPolicy, opts CatalogOptions) (Catalog, error) {
+	if opts.RecipeCap <= 0 {
+		opts.RecipeCap = DefaultCatalogRecipeCap
+	}
+	if opts.StepsBudget <= 0 {
+		opts.StepsBudget = DefaultCatalogStepsBudget
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+
+	elements := make([]string, 0, len(ds.TierMap))
+	for elem := range ds.TierMap {
+		elements = append(elements, elem)
+	}
+	for _, base := range ds.BaseElements {
+		if _, exists := ds.TierMap[base]; !exists {
+			elements = append(elements, base)
+		}
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		if ds.TierMap[elements[i]] != ds.TierMap[elements[j]] {
+			return ds.TierMap[elements[i]] < ds.TierMap[elements[j]]
+		}
+		return elements[i] < elements[j]
+	})
+
+	levels := DerivationLevels(ds, policy)
+	bounds := newStepsBounds(ds, policy, levels)
+	catalog := Catalog{
+		Dataset:     ds.Name,
+		Policy:      policy.Name(),
+		RecipeCap:   opts.RecipeCap,
+		GeneratedAt: time.Now().UTC(),
+		Elements:    make([]CatalogEntry, len(elements)),
+	}
+
+	jobs := make(chan int)
+	errs := make([]error, len(elements))
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				catalog.Elements[i], errs[i] = catalogEntry(elements[i], ds, policy, bounds, opts)
+			}
+		}()
+	}
+	for i := range elements {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return catalog, err
+		}
+	}
+	return catalog, nil
+}
+
+// catalogEntry ngitung satu baris katalog
+func catalogEntry(elem string, ds *Dataset, policy ValidityPolicy, bounds stepsBounds, opts CatalogOptions) (CatalogEntry, error) {
+	entry := CatalogEntry{Element: elem, Tier: ds.TierMap[elem], IsBase: ds.IsBase(elem), ShortestRecipe: []CatalogStep{}}
+	depth, reachable := bounds.levels[elem]
+	if !reachable {
+		return entry, nil
+	}
+	entry.Reachable = true
+	entry.MinDepth = depth
+	if entry.IsBase {
+		entry.MinStepsExact = true
+		entry.RecipeCount = 1
+		return entry, nil
+	}
+
+	// Bidirectional selalu ngasih resep lengkap dengan kedalaman minimal
+	shortest := ShortestBidirectional(elem, ds, policy)
+	if depth := RecipeScore(shortest, elem, MetricDepth); len(shortest) == 0 || depth != entry.MinDepth {
+		return entry, fmt.Errorf("shortest recipe for %s has depth %d, want minDepth %d", elem, depth, entry.MinDepth)
+	}
+	entry.ShortestRecipe = catalogSteps(shortest, elem)
+
+	// Satu resep lebih dari cap cuma buat tau jumlahnya kepotong atau nggak
+	found := MultipleDfs(context.Background(), elem, ds, policy, opts.RecipeCap+1, 1, MemoryBudget{})
+	entry.RecipeCount = min(len(found.Recipes), opts.RecipeCap)
+	entry.RecipeCountCapped = len(found.Recipes) > opts.RecipeCap
+
+	entry.MinSteps, entry.MinStepsExact = bounds.minimumSteps(elem, ds, policy, opts.StepsBudget)
+	return entry, nil
+}
+
+// catalogSteps urutin resep jadi langkah-langkah, bahan dulu baru produknya
+func catalogSteps(recipe map[string]Element, target string) []CatalogStep {
+	steps := []CatalogStep{}
+	done := make(map[string]bool)
+	var visit func(elem string)
+	visit = func(elem string) {
+		if done[elem] || isLeaf(recipeMap(recipe), elem) {
+			return
+		}
+		done[elem] = true
+		sources := recipe[elem]
+		visit(sources.Source)
+		visit(sources.Partner)
+		steps = append(steps, CatalogStep{Element: elem, Source: sources.Source, Partner: sources.Partner})
+	}
+	visit(target)
+	return steps
+}
+
+// WriteJSON nulis katalog sebagai JSON
+func (c Catalog) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// WriteCSV nulis katalog sebagai CSV, satu elemen per baris. Resep terpendek
+// ditulis "A+B=C; C+D=E".
+func (c Catalog) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"element", "tier", "isBase", "reachable", "minDepth", "minSteps",
+		"minStepsExact", "recipeCount", "recipeCountCapped", "shortestRecipe"})
+	for _, entry := range c.Elements {
+		steps := make([]string, len(entry.ShortestRecipe))
+		for i, step := range entry.ShortestRecipe {
+			steps[i] = step.Source + "+" + step.Partner + "=" + step.Element
+		}
+		out.Write([]string{
+			entry.Element,
+			strconv.Itoa(entry.Tier),
+			strconv.FormatBool(entry.IsBase),
+			strconv.FormatBool(entry.Reachable),
+			strconv.Itoa(entry.MinDepth),
+			strconv.Itoa(entry.MinSteps),
+			strconv.FormatBool(entry.MinStepsExact),
+			strconv.Itoa(entry.RecipeCount),
+			strconv.FormatBool(entry.RecipeCountCapped),
+			strings.Join(steps, "; "),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,43 @@
+package util_test
+
+import (
+	"testing"
+
+	"backend/util"
+)
+
+// checkCatalog tiap resep terpendek katalog harus lengkap, valid, dan sedalam MinDepth
+func checkCatalog(t *testing.T, ds *util.Dataset, policy util.ValidityPolicy, opts util.CatalogOptions) {
+	t.Helper()
+	catalog, err := util.BuildCatalog(ds, policy, opts)
+	if err != nil {
+		t.Fatalf("BuildCatalog (%s): %v", policy.Name(), err)
+	}
+	for _, entry := range catalog.Elements {
+		if !entry.Reachable || entry.IsBase {
+			continue
+		}
+		recipe := make(map[string]util.Element, len(entry.ShortestRecipe))
+		for _, step := range entry.ShortestRecipe {
+			recipe[step.Element] = util.Element{Source: step.Source, Partner: step.Partner}
+		}
+		if depth := checkRecipe(t, ds, policy, entry.Element, recipe); depth != entry.MinDepth {
+			t.Errorf("%s (%s): shortest recipe depth %d, minDepth %d", entry.Element, policy.Name(), depth, entry.MinDepth)
+		}
+		if entry.MinSteps < entry.MinDepth || entry.MinSteps > len(entry.ShortestRecipe) {
+			t.Errorf("%s (%s): minSteps %d outside [%d, %d]", entry.Element, policy.Name(), entry.MinSteps, entry.MinDepth, len(entry.ShortestRecipe))
+		}
+	}
+}
+
+func TestBuildCatalogFixture(t *testing.T) {
+	ds := fixtureDataset()
+	for _, policy := range testPolicies {
+		checkCatalog(t, ds, policy, util.CatalogOptions{})
+	}
+}
+
+func TestBuildCatalogRealData(t *testing.T) {
+	ds := loadRealDataset(t)
+	checkCatalog(t, ds, util.StrictTierPolicy{}, util.CatalogOptions{RecipeCap: 10, StepsBudget: 10, Workers: 4})
+}
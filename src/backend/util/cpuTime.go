@@ -0,0 +1,17 @@
+//go:build unix
+
+package util
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime CPU time (user + system) proses ini sejauh ini
+func processCPUTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package util
+
+import "time"
+
+// processCPUTime gak didukung di platform ini, SearchStats.CPUTime selalu 0
+func processCPUTime() time.Duration {
+	return 0
+}
@@ -0,0 +1,89 @@
+package util
+
+// RecipeDAG pohon resep dalam bentuk graf: tiap elemen muncul sekali sebagai node,
+// hubungan produk -> bahan jadi edge. Bagian yang dipake berkali-kali (misalnya
+// "Human" di resep yang dalam) gak diulang kayak di JSON tree biasa.
+type RecipeDAG struct {
+	Root  int       `json:"root"`
+	Nodes []DAGNode `json:"nodes"`
+	Edges []DAGEdge `json:"edges"`
+}
+
+// DAGNode satu elemen di RecipeDAG
+type DAGNode struct {
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	Tier       int      `json:"tier"`
+	Asset      string   `json:"asset,omitempty"`
+	Depth      int      `json:"depth"` // Jarak terpendek dari root
+	Uses       int      `json:"uses"`  // Berapa kali dipake sebagai bahan di resep ini
+	Byproducts []string `json:"byproducts,omitempty"`
+}
+
+// DAGEdge bahan To dipake buat bikin From. Bahan kembar (A + A) jadi dua edge.
+type DAGEdge struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// BuildDAG ubah tree hasil BuildTree/BuildReactionTree jadi RecipeDAG. Node
+// disamain per nama dan dinomorin urut BFS dari root. asset boleh nil.
+func BuildDAG(root *Node, tierMap map[string]int, asset func(element string) string) RecipeDAG {
+	dag := RecipeDAG{Nodes: []DAGNode{}, Edges: []DAGEdge{}}
+	if root == nil {
+		return dag
+	}
+
+	ids := make(map[string]int)
+	addNode := func(node *Node, depth int) int {
+		if id, exists := ids[node.Name]; exists {
+			return id
+		}
+		id := len(dag.Nodes)
+		ids[node.Name] = id
+		dagNode := DAGNode{
+			ID:         id,
+			Name:       node.Name,
+			Tier:       tierMap[node.Name],
+			Depth:      depth,
+			Byproducts: node.Byproducts,
+		}
+		if asset != nil {
+			dagNode.Asset = asset(node.Name)
+		}
+		dag.Nodes = append(dag.Nodes, dagNode)
+		return id
+	}
+
+	dag.Root = addNode(root, 0)
+	queue := []*Node{root}
+	expanded := make(map[string]bool)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if expanded[current.Name] {
+			continue
+		}
+		expanded[current.Name] = true
+
+		from := ids[current.Name]
+		for _, child := range current.Children {
+			to := addNode(child, dag.Nodes[from].Depth+1)
+			dag.Edges = append(dag.Edges, DAGEdge{From: from, To: to})
+			dag.Nodes[to].Uses++
+			if !expanded[child.Name] {
+				queue = append(queue, child)
+			}
+		}
+	}
+	return dag
+}
+
+// BuildDAGs BuildDAG buat tiap tree
+func BuildDAGs(trees []*Node, tierMap map[string]int, asset func(element string) string) []RecipeDAG {
+	dags := make([]RecipeDAG, 0, len(trees))
+	for _, tree := range trees {
+		dags = append(dags, BuildDAG(tree, tierMap, asset))
+	}
+	return dags
+}
@@ -0,0 +1,64 @@
+package util
+
+import "sync"
+
+// LittleAlchemy2BaseElements elemen yang udah kebuka dari awal di Little Alchemy 2
+// (4 elemen klasik + elemen yang cuma bisa didapet dari misi/starter)
+var LittleAlchemy2BaseElements = []string{
+	"Air", "Earth", "Fire", "Water",
+	"Clock", "Death", "Dinosaur", "Family tree",
+	"Peat", "Skeleton", "Sloth", "Tree"}
+
+// LittleAlchemy1BaseElements elemen awal Little Alchemy 1
+var LittleAlchemy1BaseElements = []string{"Air", "Earth", "Fire", "Water"}
+
+// Dataset satu set aturan alkimia: elemen dasar, tier, dan kombinasinya.
+// Semua algoritma ngambil elemen dasar dari sini, jadi mesin yang sama bisa
+// dipake buat Little Alchemy 1, 2, atau game crafting buatan sendiri.
+type Dataset struct {
+	Name            string
+	BaseElements    []string
+	Combinations    map[Pair]string
+	RevCombinations map[string][]Pair
+	TierMap         map[string]int
+	Reactions       []Reaction // Resep yang bukan dua bahan -> satu produk, lihat AddReaction
+
+	base          map[string]bool
+	levels        sync.Map // Nama policy -> DerivationLevels
+	analytics     sync.Map // Nama policy -> *GraphAnalytics
+	heuristicOnce sync.Once
+	heuristic     map[string]int // Lihat tierHeuristic
+	reactionsOnce sync.Once
+	reactions     *reactionIndex
+}
+
+// NewDataset bikin dataset dari map hasil scraper.UnmarshalRecipes dan daftar elemen dasarnya.
+// Resep N bahan atau multi-produk ditambahin lewat AddReaction.
+func NewDataset(name string, baseElements []string, combinations map[Pair]string, revCombinations map[string][]Pair, tierMap map[string]int) *Dataset {
+	ds := &Dataset{
+		Name:            name,
+		BaseElements:    baseElements,
+		Combinations:    combinations,
+		RevCombinations: revCombinations,
+		TierMap:         tierMap,
+		base:            make(map[string]bool, len(baseElements)),
+	}
+	for _, elem := range baseElements {
+		ds.base[elem] = true
+	}
+	return ds
+}
+
+// IsBase ngecek apakah element elemen dasar di dataset ini
+func (ds *Dataset) IsBase(element string) bool {
+	return ds.base[element]
+}
+
+// Has ngecek apakah element dikenal dataset ini
+func (ds *Dataset) Has(element string) bool {
+	if ds.base[element] {
+		return true
+	}
+	_, exists := ds.TierMap[element]
+	return exists
+}
@@ -0,0 +1,90 @@
+package util
+
+import "strings"
+
+// DefaultMinDistance jarak Jaccard minimal default antar resep di mode diversity
+const DefaultMinDistance = 0.3
+
+// RecipeSteps mecah fingerprint RecipeToString jadi himpunan langkah kombinasi
+// ("Elemen:A+B"), jadi dua resep bisa dibandingin per langkah
+func RecipeSteps(recipe map[string]Element, target string) map[string]bool {
+	steps := make(map[string]bool)
+	for _, step := range strings.Split(RecipeToString(recipe, target), "|") {
+		if step != "" {
+			steps[step] = true
+		}
+	}
+	return steps
+}
+
+// JaccardDistance 1 - |A∩B| / |A∪B|. Dua himpunan kosong dianggap identik.
+func JaccardDistance(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for step := range a {
+		if b[step] {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	return 1 - float64(intersection)/float64(union)
+}
+
+// SelectDiverse milih maksimal n resep dari kandidat yang saling beda secara struktur.
+// Pakai greedy farthest-point: resep pertama selalu diambil, lalu tiap langkah
+// ambil kandidat yang jarak minimalnya ke resep terpilih paling besar, asal
+// jaraknya >= minDistance. Ngembaliin index kandidat yang terpilih (urut pemilihan)
+// dan jarak minimal antar pasangan di hasil akhirnya.
+func SelectDiverse(recipes []map[string]Element, target string, n int, minDistance float64) ([]int, float64) {
+	if n <= 0 || len(recipes) == 0 {
+		return []int{}, 0
+	}
+
+	steps := make([]map[string]bool, len(recipes))
+	for i, recipe := range recipes {
+		steps[i] = RecipeSteps(recipe, target)
+	}
+
+	selected := []int{0}
+	chosen := make([]bool, len(recipes))
+	chosen[0] = true
+
+	// nearest[i] = jarak kandidat i ke resep terpilih yang paling mirip
+	nearest := make([]float64, len(recipes))
+	for i := range recipes {
+		nearest[i] = JaccardDistance(steps[i], steps[0])
+	}
+
+	spread := 1.0
+	for len(selected) < n {
+		best := -1
+		for i := range recipes {
+			if chosen[i] || nearest[i] < minDistance {
+				continue
+			}
+			if best == -1 || nearest[i] > nearest[best] {
+				best = i
+			}
+		}
+		if best == -1 {
+			break
+		}
+
+		spread = min(spread, nearest[best])
+		selected = append(selected, best)
+		chosen[best] = true
+		for i := range recipes {
+			if !chosen[i] {
+				nearest[i] = min(nearest[i], JaccardDistance(steps[i], steps[best]))
+			}
+		}
+	}
+
+	if len(selected) == 1 {
+		spread = 0
+	}
+	return selected, spread
+}
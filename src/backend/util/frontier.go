@@ -0,0 +1,268 @@
+package util
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// OverflowPolicy nentuin apa yang terjadi kalo frontier ngelewatin budget memori
+type OverflowPolicy string
+
+const (
+	// SpillOverflow mindahin kelebihan frontier ke file sementara di disk
+	SpillOverflow OverflowPolicy = "spill"
+	// PruneOverflow ngebuang kelebihan frontier (hasil bisa jadi gak lengkap)
+	PruneOverflow OverflowPolicy = "prune"
+)
+
+// MemoryBudget konfigurasi batas memori buat frontier pencarian Multiple*
+type MemoryBudget struct {
+	MaxFrontier int            // Jumlah maksimal item frontier di RAM, 0 = tanpa batas
+	Overflow    OverflowPolicy // Kebijakan kalo frontier kepenuhan, default spill
+	SpillDir    string         // Direktori buat file spill, default os.TempDir()
+}
+
+// FrontierStats ringkasan kerja frontier selama satu pencarian
+type FrontierStats struct {
+	Peak    int // Ukuran frontier terbesar di RAM
+	Pruned  int // Jumlah item yang dibuang karena budget
+	Spilled int // Jumlah item yang pernah ditulis ke disk
+}
+
+// frontierItem item frontier yang punya satu RecipeChain.
+// Pas spill, item yang nunjuk ke chain yang sama cukup ditulis sekali.
+type frontierItem[T any] interface {
+	recipe() *RecipeChain
+	withRecipe(chain *RecipeChain) T
+}
+
+// spillSegment isi satu file spill
+type spillSegment[T any] struct {
+	Recipes []*RecipeChain
+	Refs    []int // Index ke Recipes buat tiap item
+	Items   []T
+}
+
+// frontier queue (FIFO) atau stack (LIFO) yang taat sama MemoryBudget
+type frontier[T frontierItem[T]] struct {
+	lifo     bool
+	budget   MemoryBudget
+	mem      []T      // Item yang siap diambil
+	tail     []T      // FIFO: item baru yang masuk setelah ada segmen di disk
+	segments []string // File spill, urut dari yang paling lama
+	onDisk   int      // Jumlah item yang masih ada di disk
+	dir      string   // Direktori spill, dibikin pas pertama kali dibutuhin
+	stats    FrontierStats
+	err      error // Error spill pertama, setelah itu overflow jatuh ke prune
+}
+
+func newFrontier[T frontierItem[T]](budget MemoryBudget, lifo bool) *frontier[T] {
+	if budget.Overflow == "" {
+		budget.Overflow = SpillOverflow
+	}
+	return &frontier[T]{lifo: lifo, budget: budget}
+}
+
+// Len jumlah seluruh item di frontier, termasuk yang ada di disk
+func (f *frontier[T]) Len() int {
+	return len(f.mem) + len(f.tail) + f.onDisk
+}
+
+// Push nambahin item ke frontier
+func (f *frontier[T]) Push(items ...T) {
+	if len(items) == 0 {
+		return
+	}
+
+	limit := f.budget.MaxFrontier
+	switch {
+	case limit <= 0:
+		f.mem = append(f.mem, items...)
+	case f.lifo:
+		f.mem = append(f.mem, items...)
+		if len(f.mem) > limit {
+			// Yang paling bawah di stack paling lama baru diproses lagi
+			excess := len(f.mem) - limit/2
+			if !f.spill(f.mem[:excess]) {
+				excess = len(f.mem) - limit
+				f.stats.Pruned += excess
+			}
+			f.mem = append([]T(nil), f.mem[excess:]...)
+		}
+	default:
+		if len(f.segments) == 0 && len(f.tail) == 0 {
+			f.mem = append(f.mem, items...)
+			if len(f.mem) <= limit {
+				break
+			}
+			items = f.mem[limit:]
+			f.mem = f.mem[:limit:limit]
+		}
+		f.tail = append(f.tail, items...)
+		for len(f.tail) > 0 && (len(f.tail) >= limit/2 || f.overflowPrunes()) {
+			size := min(len(f.tail), max(limit/2, 1))
+			if !f.spill(f.tail[:size]) {
+				f.stats.Pruned += len(f.tail)
+				f.tail = nil
+				break
+			}
+			f.tail = append([]T(nil), f.tail[size:]...)
+		}
+	}
+
+	f.stats.Peak = max(f.stats.Peak, len(f.mem)+len(f.tail))
+}
+
+// PopBatch ngambil maksimal n item dari frontier
+func (f *frontier[T]) PopBatch(n int) []T {
+	if len(f.mem) == 0 {
+		f.refill()
+	}
+	if len(f.mem) == 0 {
+		return nil
+	}
+
+	size := min(n, len(f.mem))
+	batch := make([]T, size)
+	if f.lifo {
+		// Ambil dari atas stack
+		copy(batch, f.mem[len(f.mem)-size:])
+		f.mem = f.mem[:len(f.mem)-size]
+	} else {
+		copy(batch, f.mem[:size])
+		f.mem = f.mem[size:]
+	}
+	return batch
+}
+
+// Stats ngembaliin statistik frontier
+func (f *frontier[T]) Stats() FrontierStats {
+	return f.stats
+}
+
+// Err ngembaliin error spill pertama kalo ada
+func (f *frontier[T]) Err() error {
+	return f.err
+}
+
+// Close hapus semua file spill yang tersisa
+func (f *frontier[T]) Close() {
+	if f.dir != "" {
+		os.RemoveAll(f.dir)
+		f.dir = ""
+	}
+	f.segments = nil
+	f.onDisk = 0
+}
+
+func (f *frontier[T]) overflowPrunes() bool {
+	return f.budget.Overflow == PruneOverflow || f.err != nil
+}
+
+// refill ngisi ulang mem dari disk (atau dari tail buat FIFO)
+func (f *frontier[T]) refill() {
+	if len(f.segments) == 0 {
+		f.mem, f.tail = f.tail, nil
+		return
+	}
+
+	// FIFO ambil segmen paling lama, LIFO ambil yang paling baru
+	idx := 0
+	if f.lifo {
+		idx = len(f.segments) - 1
+	}
+	path := f.segments[idx]
+	f.segments = append(f.segments[:idx], f.segments[idx+1:]...)
+
+	items, err := readSegment[T](path)
+	os.Remove(path)
+	if err != nil {
+		if f.err == nil {
+			f.err = err
+		}
+		f.stats.Pruned += f.onDisk
+		f.onDisk = 0
+		f.segments = nil
+		f.mem, f.tail = f.tail, nil
+		return
+	}
+	f.onDisk -= len(items)
+	f.mem = items
+}
+
+// spill nulis items ke segmen baru. Ngembaliin false kalo overflow harus di-prune.
+func (f *frontier[T]) spill(items []T) bool {
+	if f.overflowPrunes() {
+		return false
+	}
+
+	if f.dir == "" {
+		dir, err := os.MkdirTemp(f.budget.SpillDir, "frontier-*")
+		if err != nil {
+			f.err = fmt.Errorf("failed to create spill directory: %v", err)
+			return false
+		}
+		f.dir = dir
+	}
+
+	path := filepath.Join(f.dir, fmt.Sprintf("segment-%d.gob", f.stats.Spilled))
+	if err := writeSegment(path, items); err != nil {
+		f.err = err
+		return false
+	}
+
+	f.segments = append(f.segments, path)
+	f.onDisk += len(items)
+	f.stats.Spilled += len(items)
+	return true
+}
+
+func writeSegment[T frontierItem[T]](path string, items []T) error {
+	segment := spillSegment[T]{Refs: make([]int, len(items)), Items: make([]T, len(items))}
+	index := make(map[*RecipeChain]int)
+	for i, item := range items {
+		chain := item.recipe()
+		ref, exists := index[chain]
+		if !exists {
+			ref = len(segment.Recipes)
+			index[chain] = ref
+			segment.Recipes = append(segment.Recipes, chain)
+		}
+		segment.Refs[i] = ref
+		segment.Items[i] = item.withRecipe(nil)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create spill file: %v", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	if err := gob.NewEncoder(w).Encode(segment); err != nil {
+		return fmt.Errorf("failed to write spill file: %v", err)
+	}
+	return w.Flush()
+}
+
+func readSegment[T frontierItem[T]](path string) ([]T, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill file: %v", err)
+	}
+	defer file.Close()
+
+	var segment spillSegment[T]
+	if err := gob.NewDecoder(bufio.NewReader(file)).Decode(&segment); err != nil {
+		return nil, fmt.Errorf("failed to read spill file: %v", err)
+	}
+
+	items := make([]T, len(segment.Items))
+	for i, item := range segment.Items {
+		items[i] = item.withRecipe(segment.Recipes[segment.Refs[i]])
+	}
+	return items, nil
+}
@@ -0,0 +1,189 @@
+package util
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// testItem item frontier yang resepnya nyimpen nomor item-nya sendiri,
+// jadi isi resep bisa dicek setelah balik dari disk
+func testItem(i int, parent *RecipeChain) BFSQueueItem {
+	name := strconv.Itoa(i)
+	chain := parent.Derive(map[string]Element{name: {Source: "A", Partner: name}})
+	return BFSQueueItem{Recipe: chain, FocusElem: name}
+}
+
+// checkItem ngecek item balik utuh: FocusElem dan resepnya
+func checkItem(t *testing.T, item BFSQueueItem, want int) {
+	t.Helper()
+	name := strconv.Itoa(want)
+	if item.FocusElem != name {
+		t.Fatalf("popped %s, want %s", item.FocusElem, name)
+	}
+	if sources, exists := item.Recipe.Get(name); !exists || sources.Partner != name {
+		t.Fatalf("item %s lost its recipe: %v", name, item.Recipe.Materialize())
+	}
+	if _, exists := item.Recipe.Get("root"); !exists {
+		t.Fatalf("item %s lost its parent entries: %v", name, item.Recipe.Materialize())
+	}
+}
+
+// runFrontier push/pop acak dibandingin sama queue/stack biasa di memori
+func runFrontier(t *testing.T, lifo bool, budget MemoryBudget) FrontierStats {
+	t.Helper()
+	f := newFrontier[BFSQueueItem](budget, lifo)
+	defer f.Close()
+
+	root := NewRecipeChain(map[string]Element{"root": {Source: "A", Partner: "B"}})
+	rng := rand.New(rand.NewSource(1))
+	var model []int
+	next := 0
+	pop := func() {
+		batch := f.PopBatch(1 + rng.Intn(5))
+		if len(batch) > len(model) {
+			t.Fatalf("popped %d items, only %d pushed", len(batch), len(model))
+		}
+		if len(batch) == 0 && len(model) > 0 {
+			t.Fatalf("PopBatch returned nothing with %d items left", len(model))
+		}
+		// LIFO ngambil n item teratas stack, urutannya tetep dari bawah ke atas
+		var want []int
+		if lifo {
+			want, model = model[len(model)-len(batch):], model[:len(model)-len(batch)]
+		} else {
+			want, model = model[:len(batch)], model[len(batch):]
+		}
+		for i, item := range batch {
+			checkItem(t, item, want[i])
+		}
+	}
+
+	for round := 0; round < 200; round++ {
+		items := make([]BFSQueueItem, rng.Intn(12))
+		for i := range items {
+			items[i] = testItem(next, root)
+			model = append(model, next)
+			next++
+		}
+		f.Push(items...)
+		if f.Len() != len(model) {
+			t.Fatalf("Len() = %d, want %d", f.Len(), len(model))
+		}
+		if rng.Intn(3) == 0 {
+			pop()
+		}
+	}
+	for len(model) > 0 {
+		pop()
+	}
+	if batch := f.PopBatch(10); len(batch) != 0 || f.Len() != 0 {
+		t.Fatalf("drained frontier still has %d items", f.Len()+len(batch))
+	}
+	if err := f.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	return f.Stats()
+}
+
+func TestFrontierSpillKeepsOrder(t *testing.T) {
+	for _, lifo := range []bool{false, true} {
+		name := "FIFO"
+		if lifo {
+			name = "LIFO"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			stats := runFrontier(t, lifo, MemoryBudget{MaxFrontier: 8, SpillDir: dir})
+			if stats.Spilled == 0 {
+				t.Errorf("nothing was spilled with MaxFrontier 8")
+			}
+			if stats.Pruned != 0 {
+				t.Errorf("spill pruned %d items", stats.Pruned)
+			}
+			if stats.Peak > 8+11 {
+				t.Errorf("peak %d items in memory, budget 8", stats.Peak)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("spill files left after Close: %v", entries)
+			}
+		})
+	}
+}
+
+func TestFrontierUnlimited(t *testing.T) {
+	stats := runFrontier(t, false, MemoryBudget{})
+	if stats.Spilled != 0 || stats.Pruned != 0 {
+		t.Errorf("unlimited frontier spilled %d and pruned %d", stats.Spilled, stats.Pruned)
+	}
+}
+
+func TestFrontierPrune(t *testing.T) {
+	for _, lifo := range []bool{false, true} {
+		f := newFrontier[BFSQueueItem](MemoryBudget{MaxFrontier: 8, Overflow: PruneOverflow}, lifo)
+		root := NewRecipeChain(nil)
+		for i := 0; i < 100; i++ {
+			f.Push(testItem(i, root))
+		}
+		popped := 0
+		for batch := f.PopBatch(3); len(batch) > 0; batch = f.PopBatch(3) {
+			popped += len(batch)
+		}
+		stats := f.Stats()
+		if stats.Spilled != 0 || stats.Pruned == 0 {
+			t.Errorf("lifo %v: spilled %d, pruned %d", lifo, stats.Spilled, stats.Pruned)
+		}
+		if popped+stats.Pruned != 100 {
+			t.Errorf("lifo %v: popped %d + pruned %d, want 100", lifo, popped, stats.Pruned)
+		}
+		if popped > 8 {
+			t.Errorf("lifo %v: kept %d items, budget 8", lifo, popped)
+		}
+		f.Close()
+	}
+}
+
+func TestFrontierCloseRemovesSpill(t *testing.T) {
+	for _, lifo := range []bool{false, true} {
+		dir := t.TempDir()
+		f := newFrontier[BFSQueueItem](MemoryBudget{MaxFrontier: 4, SpillDir: dir}, lifo)
+		root := NewRecipeChain(nil)
+		for i := 0; i < 50; i++ {
+			f.Push(testItem(i, root))
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) == 0 {
+			t.Fatalf("lifo %v: no spill directory before Close", lifo)
+		}
+		f.PopBatch(2)
+		f.Close()
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("lifo %v: spill files left after Close: %v", lifo, entries)
+		}
+	}
+}
+
+func TestFrontierSpillSharesRecipes(t *testing.T) {
+	shared := NewRecipeChain(map[string]Element{"root": {Source: "A", Partner: "B"}, "X": {Source: "A", Partner: "A"}})
+	items := []BFSQueueItem{{Recipe: shared, FocusElem: "1"}, {Recipe: shared, FocusElem: "2"}}
+
+	path := filepath.Join(t.TempDir(), "segment.gob")
+	if err := writeSegment(path, items); err != nil {
+		t.Fatal(err)
+	}
+	read, err := readSegment[BFSQueueItem](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 || read[0].FocusElem != "1" || read[1].FocusElem != "2" {
+		t.Fatalf("read %v", read)
+	}
+	if read[0].Recipe != read[1].Recipe {
+		t.Errorf("items that shared a chain got separate copies")
+	}
+	if !reflect.DeepEqual(read[0].Recipe.Materialize(), shared.Materialize()) {
+		t.Errorf("recipe %v, want %v", read[0].Recipe.Materialize(), shared.Materialize())
+	}
+}
@@ -9,63 +9,75 @@ import (
 )
 
 func ConvertToJSON(nodes []*Node, visited int, timetaken time.Duration) ([]byte, error) {
-  result := struct {
-    Recipes     []*Node       `json:"treeData"`
-    TimeTaken   string        `json:"timetaken"`
-    NodeVisited int           `json:"node_visited"`
-  }{
-    Recipes:     nodes,
-    TimeTaken:   timetaken.String(),
-    NodeVisited: visited,
-  }
-  
-  jsonData, err := json.MarshalIndent(result, "", "  ")
-  if err != nil {
-    return nil, fmt.Errorf("failed to marshal JSON: %v", err)
-  }
-  return jsonData, nil
+	result := struct {
+		Recipes     []*Node `json:"treeData"`
+		TimeTaken   string  `json:"timetaken"`
+		NodeVisited int     `json:"node_visited"`
+	}{
+		Recipes:     nodes,
+		TimeTaken:   timetaken.String(),
+		NodeVisited: visited,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+	return jsonData, nil
 }
 
 // SaveToJSON saves Node trees to a JSON file
 func SaveToJSON(nodes []*Node, filename string, visited int, timetaken time.Duration) error {
-  // Create directory if it doesn't exist
-  dir := filepath.Dir(filename)
-  if err := os.MkdirAll(dir, 0755); err != nil {
-    return fmt.Errorf("failed to create directory: %v", err)
-  }
-  
-  // Marshal the nodes to JSON
-  jsonData, err := ConvertToJSON(nodes, visited, timetaken)
-  if err != nil {
-    return fmt.Errorf("failed to marshal JSON: %v", err)
-  }
-  
-  // Write to file
-  err = os.WriteFile(filename, jsonData, 0644)
-  if err != nil {
-    return fmt.Errorf("failed to write JSON file: %v", err)
-  }
-  
-  fmt.Printf("Successfully saved to %s\n", filename)
-  return nil
+	// Create directory if it doesn't exist
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	// Marshal the nodes to JSON
+	jsonData, err := ConvertToJSON(nodes, visited, timetaken)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+
+	// Write to file
+	err = os.WriteFile(filename, jsonData, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write JSON file: %v", err)
+	}
+
+	fmt.Printf("Successfully saved to %s\n", filename)
+	return nil
 }
 
 // Node represents a node in the recipe tree
 type Node struct {
-  Name     string  `json:"name"`
-  Children []*Node `json:"children,omitempty"`
+	Name       string    `json:"name"`
+	Children   []*Node   `json:"children,omitempty"`
+	Byproducts []string  `json:"byproducts,omitempty"` // Produk lain dari reaction yang sama
+	Meta       *NodeMeta `json:"meta,omitempty"`       // Cuma diisi AnnotateTree
+}
+
+// NodeMeta info tambahan buat nampilin node tanpa join ke data elemen di frontend
+type NodeMeta struct {
+	Tier         int    `json:"tier"`
+	Asset        string `json:"asset,omitempty"`
+	Depth        int    `json:"depth"` // Jarak terpendek dari root
+	Steps        int    `json:"steps"` // Jumlah kombinasi di subtree ini, termasuk node-nya sendiri
+	IsBase       bool   `json:"isBase"`
+	Alternatives int    `json:"alternatives"` // Jumlah resep valid buat elemen ini menurut policy
 }
 
 // Element represents a recipe with two ingredients
 type Element struct {
-  Source  string
-  Partner string
+	Source  string
+	Partner string
 }
 
 // Pair represents a combination of two elements
 type Pair struct {
-  First  string
-  Second string
+	First  string
+	Second string
 }
 
 // To save to JSON
@@ -0,0 +1,318 @@
+package util
+
+import (
+	"container/heap"
+	"fmt"
+	"slices"
+)
+
+// RecipeMetric ukuran "biaya" sebuah resep buat ranking k-best
+type RecipeMetric string
+
+const (
+	// MetricSteps jumlah langkah kombinasi yang berbeda (intermediate yang sama dihitung sekali)
+	MetricSteps RecipeMetric = "steps"
+	// MetricDepth kedalaman pohon resep dari target ke elemen dasar terjauh
+	MetricDepth RecipeMetric = "depth"
+	// MetricLeaves jumlah daun pohon resep kalo dijabarkan penuh (tanpa sharing)
+	MetricLeaves RecipeMetric = "leaves"
+)
+
+// unreachableScore skor buat elemen yang gak bisa dibikin sama sekali
+const unreachableScore = 1 << 30
+
+// maxKBestExpansions batas state yang boleh diekspansi biar target yang
+// resepnya meledak gak bikin pencarian jalan selamanya
+const maxKBestExpansions = 500000
+
+// ParseRecipeMetric ngubah string dari request jadi RecipeMetric
+func ParseRecipeMetric(name string) (RecipeMetric, error) {
+	switch RecipeMetric(name) {
+	case MetricSteps, MetricDepth, MetricLeaves:
+		return RecipeMetric(name), nil
+	}
+	return "", fmt.Errorf("unsupported metric %q", name)
+}
+
+// RankedRecipe resep beserta skornya
+type RankedRecipe struct {
+	Recipe map[string]Element
+	Score  int
+}
+
+// KBestResult hasil KBestRecipes
+type KBestResult struct {
+	Recipes  []RankedRecipe // Urut dari skor paling kecil
+	Expanded int            // Jumlah state parsial yang diekspansi
+	Stats    SearchStats    // Statistik eksplorasi pencarian
+}
+
+// kBestState resep parsial di priority queue.
+// Elemen di open udah dipake pohon tapi belum dipilihin resepnya.
+type kBestState struct {
+	recipe   *RecipeChain
+	open     []string
+	assigned int
+	score    int // Lower bound skor semua resep lengkap turunan state ini
+	seq      int // Urutan masuk, buat tie-break yang deterministik
+}
+
+type kBestQueue []*kBestState
+
+func (q kBestQueue) Len() int { return len(q) }
+func (q kBestQueue) Less(i, j int) bool {
+	if q[i].score != q[j].score {
+		return q[i].score < q[j].score
+	}
+	return q[i].seq < q[j].seq
+}
+func (q kBestQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *kBestQueue) Push(x any)   { *q = append(*q, x.(*kBestState)) }
+func (q *kBestQueue) Pop() any {
+	old := *q
+	state := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return state
+}
+
+// KBestRecipes nyari k resep berbeda dengan skor terkecil menurut metric.
+// Pencariannya best-first di atas resep parsial: tiap state dikasih lower bound
+// yang admissible, jadi resep lengkap keluar dari queue udah urut dari yang
+// terbaik, tanpa harus generate semua resep dulu baru di-sort.
+func KBestRecipes(target string, ds *Dataset, policy ValidityPolicy, k int, metric RecipeMetric) KBestResult {
+	return kBestRecipes(target, ds, policy, k, metric, maxKBestExpansions, minimalScores(ds, policy, metric))
+}
+
+// kBestRecipes KBestRecipes dengan batas ekspansi sendiri, buat pemanggil yang
+// lebih milih nyerah cepet (misalnya minimumSteps yang jalan di semua elemen).
+// bounds hasil minimalScores(ds, policy, metric), biar bisa dihitung sekali aja.
+func kBestRecipes(target string, ds *Dataset, policy ValidityPolicy, k int, metric RecipeMetric, maxExpansions int, bounds map[string]int) (result KBestResult) {
+	c := newSearchCounters()
+	result = KBestResult{Recipes: []RankedRecipe{}}
+	defer func() { result.Stats = c.finish() }()
+	if k <= 0 {
+		return result
+	}
+
+	// Elemen dasar cuma punya satu "resep": dirinya sendiri
+	if ds.IsBase(target) {
+		result.Recipes = append(result.Recipes, RankedRecipe{Recipe: map[string]Element{}, Score: 0})
+		return result
+	}
+
+	if _, reachable := bounds[target]; !reachable {
+		return result
+	}
+
+	// Cache pasangan valid yang udah dinormalisasi (A+B sama dengan B+A)
+	pairCache := make(map[string][]Pair)
+	validPairsOf := func(elem string) []Pair {
+		if pairs, exists := pairCache[elem]; exists {
+			return pairs
+		}
+		pairs := uniquePairs(filterValidPairs(ds.RevCombinations[elem], elem, ds.TierMap, policy))
+		pairCache[elem] = pairs
+		return pairs
+	}
+
+	seq := 0
+	queue := &kBestQueue{}
+	root := &kBestState{recipe: NewRecipeChain(nil), open: []string{target}}
+	root.score = boundScore(root, target, metric, bounds)
+	heap.Push(queue, root)
+
+	for queue.Len() > 0 && len(result.Recipes) < k && result.Expanded < maxExpansions {
+		c.observeFrontier(queue.Len())
+		state := heap.Pop(queue).(*kBestState)
+
+		// Gak ada elemen terbuka: resep lengkap, skornya udah pasti
+		if len(state.open) == 0 {
+			result.Recipes = append(result.Recipes, RankedRecipe{
+				Recipe: state.recipe.Materialize(),
+				Score:  state.score,
+			})
+			continue
+		}
+		result.Expanded++
+		c.expand()
+
+		// Ekspansi elemen terbuka paling atas dengan tiap pasangan validnya
+		elem := state.open[len(state.open)-1]
+		rest := state.open[:len(state.open)-1]
+
+		for _, pair := range validPairsOf(elem) {
+			c.examine(1)
+
+			// Elemen yang butuh dirinya sendiri gak pernah jadi resep valid
+			if pair.First == elem || pair.Second == elem {
+				continue
+			}
+
+			open := append([]string(nil), rest...)
+			for _, ingredient := range []string{pair.First, pair.Second} {
+				if ds.IsBase(ingredient) {
+					continue
+				}
+				if _, assigned := state.recipe.Get(ingredient); assigned {
+					continue
+				}
+				if slices.Contains(open, ingredient) {
+					continue
+				}
+				open = append(open, ingredient)
+			}
+
+			seq++
+			child := &kBestState{
+				recipe:   state.recipe.Derive(map[string]Element{elem: {Source: pair.First, Partner: pair.Second}}),
+				open:     open,
+				assigned: state.assigned + 1,
+				seq:      seq,
+			}
+			// Policy selain strict tier bisa bikin bahan yang udah ada butuh elem lagi
+			if recipeHasCycle(child.recipe, target) {
+				continue
+			}
+			child.score = boundScore(child, target, metric, bounds)
+			if child.score >= unreachableScore {
+				continue
+			}
+			heap.Push(queue, child)
+		}
+	}
+
+	return result
+}
+
+// RecipeScore ngitung skor pasti sebuah resep lengkap menurut metric
+func RecipeScore(recipe map[string]Element, target string, metric RecipeMetric) int {
+	return evaluateScore(recipeMap(recipe), target, metric, nil)
+}
+
+// boundScore lower bound skor state: elemen terbuka dinilai pakai skor minimalnya
+func boundScore(state *kBestState, target string, metric RecipeMetric, bounds map[string]int) int {
+	if metric == MetricSteps {
+		// Tiap elemen terbuka butuh minimal satu langkah lagi,
+		// dan state yang punya elemen terbuka mustahil langsung dibuang
+		for _, elem := range state.open {
+			if _, reachable := bounds[elem]; !reachable {
+				return unreachableScore
+			}
+		}
+		return state.assigned + len(state.open)
+	}
+	return evaluateScore(state.recipe, target, metric, bounds)
+}
+
+// evaluateScore ngitung depth/leaves pohon resep. Elemen tanpa resep dinilai
+// pakai bounds (kalo ada, elemen dasar udah ada di situ), kalo gak ada dianggap
+// elemen dasar yang bernilai 0 (depth) atau 1 (leaves).
+func evaluateScore(recipe recipeReader, target string, metric RecipeMetric, bounds map[string]int) int {
+	if metric == MetricSteps {
+		return len(reachableElements(recipe, target))
+	}
+
+	memo := make(map[string]int)
+	inProgress := make(map[string]bool)
+
+	var score func(elem string) int
+	score = func(elem string) int {
+		if value, exists := memo[elem]; exists {
+			return value
+		}
+		// Resep lengkap: elemen tanpa resep pasti elemen dasar
+		if bounds == nil && isLeaf(recipe, elem) {
+			return baseScore(metric)
+		}
+		sources, exists := recipe.Get(elem)
+		if !exists || sources.Source == "" || inProgress[elem] {
+			if bound, ok := bounds[elem]; ok {
+				return bound
+			}
+			return unreachableScore
+		}
+
+		inProgress[elem] = true
+		value := combineScores(metric, score(sources.Source), score(sources.Partner))
+		inProgress[elem] = false
+
+		memo[elem] = value
+		return value
+	}
+
+	return score(target)
+}
+
+// minimalScores ngitung skor minimal tiap elemen (depth/leaves) di semua resep validnya.
+// Dipake sebagai heuristik admissible buat elemen yang masih terbuka. Buat steps
+// yang dihitung depth-nya, cuma dipake buat tau elemen mana yang mustahil dibikin.
+func minimalScores(ds *Dataset, policy ValidityPolicy, metric RecipeMetric) map[string]int {
+	scores := make(map[string]int)
+	if metric == MetricSteps {
+		metric = MetricDepth
+	}
+
+	for _, base := range ds.BaseElements {
+		scores[base] = baseScore(metric)
+	}
+	get := func(elem string) int {
+		if value, exists := scores[elem]; exists {
+			return value
+		}
+		return unreachableScore
+	}
+
+	// Relaksasi sampai gak ada skor yang berubah lagi
+	for changed := true; changed; {
+		changed = false
+		for elem, pairs := range ds.RevCombinations {
+			if ds.IsBase(elem) {
+				continue
+			}
+			best := get(elem)
+			for _, pair := range filterValidPairs(pairs, elem, ds.TierMap, policy) {
+				if value := combineScores(metric, get(pair.First), get(pair.Second)); value < best {
+					best = value
+				}
+			}
+			if best < get(elem) {
+				scores[elem] = best
+				changed = true
+			}
+		}
+	}
+
+	return scores
+}
+
+func baseScore(metric RecipeMetric) int {
+	if metric == MetricLeaves {
+		return 1
+	}
+	return 0
+}
+
+func combineScores(metric RecipeMetric, first, second int) int {
+	if first >= unreachableScore || second >= unreachableScore {
+		return unreachableScore
+	}
+	if metric == MetricLeaves {
+		return min(first+second, unreachableScore)
+	}
+	return 1 + max(first, second)
+}
+
+// uniquePairs ngebuang pasangan kembar hasil penyimpanan A+B dan B+A
+func uniquePairs(pairs []Pair) []Pair {
+	seen := make(map[string]bool)
+	result := []Pair{}
+	for _, pair := range pairs {
+		key := NormalizeIngredients(pair.First, pair.Second)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, pair)
+	}
+	return result
+}
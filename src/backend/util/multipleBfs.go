@@ -1,6 +1,7 @@
 package util
 
 import (
+	"context"
 	"runtime"
 	"sync"
 	"time"
@@ -8,8 +9,15 @@ import (
 
 // BFSQueueItem struktur data yang disimpan dalam queue untuk BFS
 type BFSQueueItem struct {
-	Recipe    map[string]Element // Resep saat ini
-	FocusElem string            // Elemen yang lagi difokuskan
+	Recipe    *RecipeChain // Resep saat ini (berbagi struktur dengan parent-nya)
+	FocusElem string       // Elemen yang lagi difokuskan
+}
+
+func (item BFSQueueItem) recipe() *RecipeChain { return item.Recipe }
+
+func (item BFSQueueItem) withRecipe(chain *RecipeChain) BFSQueueItem {
+	item.Recipe = chain
+	return item
 }
 
 // BFSWorkBatch berisi sekumpulan item yang akan diproses oleh worker
@@ -19,44 +27,51 @@ type BFSWorkBatch struct {
 
 // BFSProcessingResult menyimpan hasil pemrosesan dari satu worker
 type BFSProcessingResult struct {
-	NewRecipes      []map[string]Element // Resep-resep baru yang ditemukan
-	NewQueueItems   []BFSQueueItem       // Item-item baru untuk dimasukkan ke queue
-	VisitedElements map[string]bool      // Elemen-elemen yang dikunjungi selama pemrosesan
+	NewRecipes      []*RecipeChain  // Resep-resep baru yang ditemukan
+	NewQueueItems   []BFSQueueItem  // Item-item baru untuk dimasukkan ke queue
+	VisitedElements map[string]bool // Elemen-elemen yang dikunjungi selama pemrosesan
+	Expanded        int             // Jumlah item yang diekspansi
 }
 
 // MultipleBfs implementasi BFS yang dioptimasi dengan paralelisasi
-// untuk mencari beberapa resep valid untuk elemen target
-func MultipleBfs(target string, combinations map[Pair]string, revCombinations map[string][]Pair, 
-                         tierMap map[string]int, maxRecipes int, numWorkers int) MultipleRecipesResult {
+// untuk mencari beberapa resep valid untuk elemen target.
+// Frontier dibatasi budget: kelebihannya di-spill ke disk atau di-prune.
+// Pencarian berhenti lebih awal kalo ctx dibatalin.
+func MultipleBfs(ctx context.Context, target string, ds *Dataset, policy ValidityPolicy, maxRecipes int, numWorkers int, budget MemoryBudget) MultipleRecipesResult {
 	// Set jumlah worker ke jumlah CPU jika tidak ditentukan
 	if numWorkers <= 0 {
 		numWorkers = runtime.NumCPU()
 	}
 
+	c := newSearchCounters()
+
 	// Pertama, cari resep awal pake ShortestBfs
-	firstRecipe := ShortestBfs(target, combinations, tierMap)
-	
+	c.subSearch()
+	firstRecipe, found := shortestBfs(target, ds, policy, c)
+
 	// Pantau semua elemen yang udah dikunjungi
 	visited := make(map[string]bool)
 	visitedMutex := &sync.Mutex{}
-	
+
 	// Masukin elemen dasar ke visited
-	for _, elem := range BaseElements {
+	for _, elem := range ds.BaseElements {
 		visited[elem] = true
 	}
-	
+
 	// Kalo gak nemu resep awal, yaudah return hasil kosong
-	if len(firstRecipe) == 0 {
+	if !found {
 		return MultipleRecipesResult{
 			Recipes:   []map[string]Element{},
 			NodeCount: len(visited),
+			Stats:     c.finish(),
 		}
 	}
-	
+
 	// Kumpulan resep, mulai dari resep pertama
-	recipes := []map[string]Element{firstRecipe}
+	firstChain := NewRecipeChain(firstRecipe)
+	recipes := []*RecipeChain{firstChain}
 	recipesMutex := &sync.Mutex{}
-	
+
 	// Catat elemen di resep untuk ngukur berapa node yang dikunjungi
 	for elem := range firstRecipe {
 		visited[elem] = true
@@ -65,33 +80,40 @@ func MultipleBfs(target string, combinations map[Pair]string, revCombinations ma
 	// Track recipes we've already seen to avoid duplicates
 	// Pake concurrent map buat nyimpen resep yang udah ditemuin, biar gak duplikat
 	seenRecipes := sync.Map{}
-	
+
 	// Mark first recipe as seen
 	seenRecipes.Store(RecipeToString(firstRecipe, target), true)
-	
-	// Initial BFS queue
-	initialQueue := []BFSQueueItem{}
-	
+
+	// Create a shared queue protected by a mutex
+	queue := newFrontier[BFSQueueItem](budget, false)
+	defer queue.Close()
+	queueMutex := &sync.Mutex{}
+
+	// Jumlah batch yang lagi diproses worker, biar frontier kosong sesaat
+	// gak dianggap pencarian udah selesai
+	inFlight := 0
+	finishBatch := func() {
+		queueMutex.Lock()
+		inFlight--
+		queueMutex.Unlock()
+	}
+
 	// First add target variations
-	initialQueue = append(initialQueue, BFSQueueItem{Recipe: firstRecipe, FocusElem: target})
-	
+	queue.Push(BFSQueueItem{Recipe: firstChain, FocusElem: target})
+
 	// Then add component variations
-	for elem := range firstRecipe {
-		if !isBaseElement(elem) && elem != target {
-			initialQueue = append(initialQueue, BFSQueueItem{Recipe: firstRecipe, FocusElem: elem})
+	for _, elem := range reachableElements(firstChain, target) {
+		if elem != target {
+			queue.Push(BFSQueueItem{Recipe: firstChain, FocusElem: elem})
 		}
 	}
-	
-	// Create a shared queue protected by a mutex
-	queue := initialQueue
-	queueMutex := &sync.Mutex{}
-	
+
 	// Create a WaitGroup to synchronize worker goroutines
 	var wg sync.WaitGroup
-	
+
 	// Channel buat kasih sinyal worker untuk berhenti
 	done := make(chan struct{})
-	
+
 	// Pake sync.Once buat mastiin kita cuma nutup channel done sekali
 	var closeOnce sync.Once
 	signalDone := func() {
@@ -99,46 +121,42 @@ func MultipleBfs(target string, combinations map[Pair]string, revCombinations ma
 			close(done)
 		})
 	}
-	
+
 	// Function to get a batch of work from the queue
 	getBatch := func(batchSize int) []BFSQueueItem {
 		queueMutex.Lock()
 		defer queueMutex.Unlock()
-		
-		if len(queue) == 0 {
-			return nil
-		}
-		
+
 		// Get up to batchSize items, or all remaining items if less
-		size := batchSize
-		if size > len(queue) {
-			size = len(queue)
+		batch := queue.PopBatch(batchSize)
+		if batch != nil {
+			inFlight++
 		}
-		
-		batch := queue[:size]
-		queue = queue[size:]
-		
 		return batch
 	}
-	
+
 	// Function to add items to the queue
 	addToQueue := func(items []BFSQueueItem) {
 		if len(items) == 0 {
 			return
 		}
-		
+
 		queueMutex.Lock()
 		defer queueMutex.Unlock()
-		
-		queue = append(queue, items...)
+
+		queue.Push(items...)
 	}
-	
+
+	// Statistik per worker, tiap worker cuma nulis ke punyanya sendiri
+	workerStats := make([]WorkerStats, numWorkers)
+
 	// Worker function yang memproses batch pekerjaan
-	worker := func() {
+	worker := func(id int) {
 		defer wg.Done()
-		
+		ws := &workerStats[id]
+
 		localVisited := make(map[string]bool)
-		
+
 		// Proses batch sampai diberi sinyal untuk berhenti
 		for {
 			select {
@@ -160,59 +178,67 @@ func MultipleBfs(target string, combinations map[Pair]string, revCombinations ma
 					runtime.Gosched() // Kasih kesempatan goroutine lain jalan
 					continue
 				}
-				
-				result := processBatch(batch, combinations, revCombinations, tierMap, 
-					&seenRecipes, localVisited, target)
-				
+
+				result := processBatch(batch, ds, policy,
+					&seenRecipes, localVisited, target, c)
+				ws.Batches++
+				ws.NodesExpanded += int64(result.Expanded)
+				ws.RecipesFound += int64(len(result.NewRecipes))
+
 				// Tangani hasilnya
 				if len(result.NewRecipes) > 0 {
 					recipesMutex.Lock()
 					recipes = append(recipes, result.NewRecipes...)
-					
+
 					// Cek udah nyampe max recipes belum
 					reachedMax := maxRecipes > 0 && len(recipes) >= maxRecipes
 					recipesMutex.Unlock()
-					
+
 					if reachedMax {
 						signalDone() // Pake fungsi signalDone yang aman
 						return
 					}
 				}
-				
+
 				// Tambahin item baru ke queue
 				if len(result.NewQueueItems) > 0 {
 					addToQueue(result.NewQueueItems)
 				}
+				finishBatch()
 			}
 		}
 	}
-	
+
 	// Start worker goroutines
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
-		go worker()
+		go worker(i)
 	}
-	
+
 	// Periksa berkala apakah masih ada kerjaan tersisa dan semua worker sedang idle
 	go func() {
 		ticker := time.NewTicker(50 * time.Millisecond)
 		defer ticker.Stop()
-		
+
 		for {
 			select {
 			case <-done:
 				return
+			case <-ctx.Done():
+				// Dibatalin pemanggil (mis. job batch), resep yang udah ketemu tetap dikembaliin
+				signalDone()
+				return
 			case <-ticker.C:
 				queueMutex.Lock()
-				queueEmpty := len(queue) == 0
+				queueEmpty := queue.Len() == 0 && inFlight == 0
 				queueMutex.Unlock()
-				
+
 				if queueEmpty {
 					// Gak ada kerjaan tersisa di queue - cek apa kita perlu berhenti
 					recipesMutex.Lock()
 					reachedMax := maxRecipes > 0 && len(recipes) >= maxRecipes
 					recipesMutex.Unlock()
-					
+
 					if reachedMax || queueEmpty {
 						// Kita udah punya cukup resep atau udah proses semua
 						signalDone() // Pake fungsi signalDone yang aman
@@ -222,109 +248,122 @@ func MultipleBfs(target string, combinations map[Pair]string, revCombinations ma
 			}
 		}
 	}()
-	
+
 	// Wait for all workers to finish
 	wg.Wait()
-	
+
 	// Merge any remaining local visited maps into the global one
 	// This is handled in the worker exit code
-	
+
+	// Frontier peak diambil dari queue utama, bukan dari pencarian kecil di dalamnya
+	stats := c.finish()
+	stats.FrontierPeak = queue.Stats().Peak
+	stats.Workers = workerStats
+
+	// Cuma resep yang bakal dikembaliin yang dimaterialisasi jadi map
 	return MultipleRecipesResult{
-		Recipes:   recipes,
+		Recipes:   materializeRecipes(recipes, maxRecipes),
 		NodeCount: len(visited),
+		Frontier:  queue.Stats(),
+		Stats:     stats,
 	}
 }
 
 // processBatch handles processing a batch of queue items
 // Returns new recipes, new queue items, and visited elements
-func processBatch(batch []BFSQueueItem, combinations map[Pair]string, 
-                 revCombinations map[string][]Pair, tierMap map[string]int,
-                 seenRecipes *sync.Map, localVisited map[string]bool,
-                 target string) BFSProcessingResult { // Add target parameter here
+func processBatch(batch []BFSQueueItem, ds *Dataset, policy ValidityPolicy,
+	seenRecipes *sync.Map, localVisited map[string]bool,
+	target string, c *searchCounters) BFSProcessingResult {
 	result := BFSProcessingResult{
-		NewRecipes:      make([]map[string]Element, 0),
+		NewRecipes:      make([]*RecipeChain, 0),
 		NewQueueItems:   make([]BFSQueueItem, 0),
 		VisitedElements: make(map[string]bool),
 	}
-	
+
 	// Process each queue item in the batch
 	for _, current := range batch {
 		// Skip base elements
-		if isBaseElement(current.FocusElem) {
+		if ds.IsBase(current.FocusElem) {
 			continue
 		}
-		
+
+		result.Expanded++
+		c.expand()
+
 		// Current recipe and focus element
 		currentRecipe := current.Recipe
 		focusElem := current.FocusElem
-		
+
 		// Original recipe for this element
-		originalSources := currentRecipe[focusElem]
-		
+		originalSources, _ := currentRecipe.Get(focusElem)
+
 		// Get all valid ways to make this element
-		validPairs := filterValidPairs(revCombinations[focusElem], focusElem, tierMap)
-		
+		validPairs := filterValidPairs(ds.RevCombinations[focusElem], focusElem, ds.TierMap, policy)
+
 		// Try each alternative way to make this element
 		for _, pair := range validPairs {
+			c.examine(1)
+
 			// Skip the current recipe for this element
 			if (pair.First == originalSources.Source && pair.Second == originalSources.Partner) ||
 				(pair.First == originalSources.Partner && pair.Second == originalSources.Source) {
 				continue
 			}
-			
-			// Create a variation with this alternative
-			variation := copyRecipe(currentRecipe)
-			variation[focusElem] = Element{Source: pair.First, Partner: pair.Second}
-			
+
+			// Create a variation with this alternative, tanpa nyalin resep dasarnya
+			variation := newRecipeOverlay(currentRecipe)
+			variation.Set(focusElem, Element{Source: pair.First, Partner: pair.Second})
+
 			// Ensure the new ingredients have valid recipes if they're not already in our recipe
 			allValid := true
-			
+
 			for _, ingredient := range []string{pair.First, pair.Second} {
-				if isBaseElement(ingredient) {
+				if ds.IsBase(ingredient) {
 					continue // Base elements are always valid
 				}
-				
+
 				// If we don't have a recipe for this ingredient yet, find one
-				if _, exists := variation[ingredient]; !exists {
-					ingredientRecipe := findIngredientRecipe(ingredient, combinations, revCombinations, tierMap, localVisited)
+				if _, exists := variation.Get(ingredient); !exists {
+					ingredientRecipe := findIngredientRecipe(ingredient, ds, policy, localVisited, c)
 					if len(ingredientRecipe) == 0 {
 						allValid = false
 						break
 					}
-					
+
 					// Add the ingredient's recipe to our variation
 					for elem, sources := range ingredientRecipe {
-						if _, exists := variation[elem]; !exists {
-							variation[elem] = sources
+						if _, exists := variation.Get(elem); !exists {
+							variation.Set(elem, sources)
 							localVisited[elem] = true
 							result.VisitedElements[elem] = true
 						}
 					}
 				}
 			}
-			
-			if !allValid {
+
+			if !allValid || recipeHasCycle(variation, target) {
 				continue // Skip this variation if we couldn't complete it
 			}
-			
+
 			// Check if this is a unique recipe
-			recipeStr := RecipeToString(variation, target)
-			if _, seen := seenRecipes.LoadOrStore(recipeStr, true); !seen {
+			recipeStr := recipeFingerprint(variation, target)
+			if _, seen := seenRecipes.LoadOrStore(recipeStr, true); seen {
+				c.duplicate()
+			} else {
 				// Add to new recipes
-				result.NewRecipes = append(result.NewRecipes, variation)
-				
+				chain := variation.Commit()
+				result.NewRecipes = append(result.NewRecipes, chain)
+
 				// Add variations for each component in our recipe (BFS approach)
-				for elem := range variation {
-					if !isBaseElement(elem) {
-						result.NewQueueItems = append(result.NewQueueItems, BFSQueueItem{
-							Recipe:    variation,
-							FocusElem: elem,
-						})
-					}
+				for _, elem := range reachableElements(chain, target) {
+					result.NewQueueItems = append(result.NewQueueItems, BFSQueueItem{
+						Recipe:    chain,
+						FocusElem: elem,
+					})
 				}
 			}
 		}
 	}
-	
+
 	return result
 }
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"context"
 	"runtime"
 	"sync"
 	"sync/atomic" // Tambahkan import untuk atomic
@@ -9,48 +10,63 @@ import (
 
 // DFSWorkItem represents an element to be processed in our DFS algorithm
 type DFSWorkItem struct {
-	Element       string               // Elemen yang sedang difokuskan
-	BaseRecipes   []map[string]Element // Resep-resep yang sedang dikerjakan
-	ExploredPairs map[string]bool      // Pairs yang sudah dieksplorasi untuk element ini
+	Element       string          // Elemen yang sedang difokuskan
+	Recipe        *RecipeChain    // Resep yang sedang dikerjakan
+	ExploredPairs map[string]bool // Pairs yang sudah dieksplorasi untuk element ini
+}
+
+func (item DFSWorkItem) recipe() *RecipeChain { return item.Recipe }
+
+func (item DFSWorkItem) withRecipe(chain *RecipeChain) DFSWorkItem {
+	item.Recipe = chain
+	return item
 }
 
 // DFSProcessingResult menyimpan hasil pemrosesan dari satu worker DFS
 type DFSProcessingResult struct {
-	NewRecipes      []map[string]Element // Resep baru yang ditemukan
-	NewWorkItems    []DFSWorkItem        // Item kerja baru untuk diproses
-	VisitedElements map[string]bool      // Elemen yang dikunjungi
+	NewRecipes      []*RecipeChain  // Resep baru yang ditemukan
+	NewWorkItems    []DFSWorkItem   // Item kerja baru untuk diproses
+	VisitedElements map[string]bool // Elemen yang dikunjungi
+	Expanded        int             // Jumlah item yang diekspansi
 }
 
 // MultipleDfs implementasi DFS yang diparalelkan
-// Menggunakan atomic counter untuk melacak jumlah resep yang dihasilkan
-func MultipleDfs(target string, revCombinations map[string][]Pair, tierMap map[string]int, maxRecipes int, numWorkers int) MultipleRecipesResult {
+// Menggunakan atomic counter untuk melacak jumlah resep yang dihasilkan.
+// Work stack dibatasi budget: bagian bawah stack di-spill ke disk atau di-prune.
+// Pencarian berhenti lebih awal kalo ctx dibatalin.
+func MultipleDfs(ctx context.Context, target string, ds *Dataset, policy ValidityPolicy, maxRecipes int, numWorkers int, budget MemoryBudget) MultipleRecipesResult {
 	// Set jumlah worker optimal jika tidak ditentukan
 	if numWorkers <= 0 {
 		numWorkers = runtime.NumCPU()
 	}
 
+	c := newSearchCounters()
+
 	// Pertama, cari resep awal pakai ShortestDfs
-	firstRecipe := ShortestDfs(target, revCombinations, tierMap)
+	c.subSearch()
+	firstRecipe, found := shortestDfs(target, ds, policy, c)
 
 	// Pantau elemen yang sudah dikunjungi
 	visited := make(map[string]bool)
 	visitedMutex := &sync.Mutex{}
 
 	// Tambahkan elemen dasar ke visited
-	for _, elem := range BaseElements {
+	for _, elem := range ds.BaseElements {
 		visited[elem] = true
 	}
 
 	// Jika tidak menemukan resep awal, return hasil kosong
-	if len(firstRecipe) == 0 {
+	if !found {
 		return MultipleRecipesResult{
 			Recipes:   []map[string]Element{},
 			NodeCount: len(visited),
+			Stats:     c.finish(),
 		}
 	}
 
 	// Kumpulan resep, mulai dari resep pertama
-	recipes := []map[string]Element{firstRecipe}
+	firstChain := NewRecipeChain(firstRecipe)
+	recipes := []*RecipeChain{firstChain}
 	recipesMutex := &sync.Mutex{}
 
 	// Catat elemen di resep untuk mengukur berapa node yang dikunjungi
@@ -70,16 +86,17 @@ func MultipleDfs(target string, revCombinations map[string][]Pair, tierMap map[s
 	seenRecipes.Store(seenRecipeKey, true)
 
 	// Cari elemen-elemen yang memiliki alternatif untuk dieksplorasi
-	elementsToExplore := findElementsWithAlternatives(target, firstRecipe, revCombinations, tierMap)
+	elementsToExplore := findElementsWithAlternatives(target, firstRecipe, ds, policy)
 
 	// Buat work stack awal untuk DFS
-	workStack := make([]DFSWorkItem, 0, len(elementsToExplore))
-	
+	workStack := newFrontier[DFSWorkItem](budget, true)
+	defer workStack.Close()
+
 	// Isi work stack dengan elemen-elemen yang perlu dieksplorasi
 	for _, elem := range elementsToExplore {
-		workStack = append(workStack, DFSWorkItem{
+		workStack.Push(DFSWorkItem{
 			Element:       elem,
-			BaseRecipes:   []map[string]Element{firstRecipe},
+			Recipe:        firstChain,
 			ExploredPairs: make(map[string]bool),
 		})
 	}
@@ -87,6 +104,15 @@ func MultipleDfs(target string, revCombinations map[string][]Pair, tierMap map[s
 	// Mutex untuk mengamankan akses ke work stack
 	workStackMutex := &sync.Mutex{}
 
+	// Jumlah batch yang lagi diproses worker, biar frontier kosong sesaat
+	// gak dianggap pencarian udah selesai
+	inFlight := 0
+	finishBatch := func() {
+		workStackMutex.Lock()
+		inFlight--
+		workStackMutex.Unlock()
+	}
+
 	// Channel untuk memberi sinyal worker untuk berhenti
 	done := make(chan struct{})
 
@@ -103,21 +129,11 @@ func MultipleDfs(target string, revCombinations map[string][]Pair, tierMap map[s
 		workStackMutex.Lock()
 		defer workStackMutex.Unlock()
 
-		if len(workStack) == 0 {
-			return nil
-		}
-
-		// Ambil maksimal batchSize item, atau semua item yang tersisa jika lebih sedikit
-		size := batchSize
-		if size > len(workStack) {
-			size = len(workStack)
-		}
-
 		// Ambil item dari atas stack (pendekatan DFS)
-		startIdx := len(workStack) - size
-		batch := workStack[startIdx:]
-		workStack = workStack[:startIdx]
-
+		batch := workStack.PopBatch(batchSize)
+		if batch != nil {
+			inFlight++
+		}
 		return batch
 	}
 
@@ -131,15 +147,19 @@ func MultipleDfs(target string, revCombinations map[string][]Pair, tierMap map[s
 		defer workStackMutex.Unlock()
 
 		// Tambahkan ke atas stack untuk diproses selanjutnya (prioritas DFS)
-		workStack = append(workStack, items...)
+		workStack.Push(items...)
 	}
 
 	// Buat WaitGroup untuk menyinkronkan worker goroutine
 	var wg sync.WaitGroup
 
+	// Statistik per worker, tiap worker cuma nulis ke punyanya sendiri
+	workerStats := make([]WorkerStats, numWorkers)
+
 	// Worker function yang memproses batch pekerjaan
-	worker := func() {
+	worker := func(id int) {
 		defer wg.Done()
+		ws := &workerStats[id]
 
 		localVisited := make(map[string]bool)
 
@@ -171,7 +191,10 @@ func MultipleDfs(target string, revCombinations map[string][]Pair, tierMap map[s
 					continue
 				}
 
-				result := processWorkBatchAtomic(batch, revCombinations, tierMap, &seenRecipes, localVisited, target, maxRecipes, &recipeCounter)
+				result := processWorkBatchAtomic(batch, ds, policy, &seenRecipes, localVisited, target, maxRecipes, &recipeCounter, c)
+				ws.Batches++
+				ws.NodesExpanded += int64(result.Expanded)
+				ws.RecipesFound += int64(len(result.NewRecipes))
 
 				// Tangani hasil pemrosesan
 				if len(result.NewRecipes) > 0 {
@@ -184,6 +207,7 @@ func MultipleDfs(target string, revCombinations map[string][]Pair, tierMap map[s
 				if len(result.NewWorkItems) > 0 {
 					addToWorkStack(result.NewWorkItems)
 				}
+				finishBatch()
 			}
 		}
 	}
@@ -191,7 +215,7 @@ func MultipleDfs(target string, revCombinations map[string][]Pair, tierMap map[s
 	// Mulai worker goroutine
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
-		go worker()
+		go worker(i)
 	}
 
 	// Periksa secara berkala apakah masih ada pekerjaan tersisa dan semua worker sedang idle
@@ -203,9 +227,13 @@ func MultipleDfs(target string, revCombinations map[string][]Pair, tierMap map[s
 			select {
 			case <-done:
 				return
+			case <-ctx.Done():
+				// Dibatalin pemanggil (mis. job batch), resep yang udah ketemu tetap dikembaliin
+				signalDone()
+				return
 			case <-ticker.C:
 				workStackMutex.Lock()
-				stackEmpty := len(workStack) == 0
+				stackEmpty := workStack.Len() == 0 && inFlight == 0
 				workStackMutex.Unlock()
 
 				// Periksa apakah kita sudah memiliki cukup resep
@@ -226,20 +254,26 @@ func MultipleDfs(target string, revCombinations map[string][]Pair, tierMap map[s
 	// Tunggu semua worker selesai
 	wg.Wait()
 
+	// Frontier peak diambil dari stack utama, bukan dari pencarian kecil di dalamnya
+	stats := c.finish()
+	stats.FrontierPeak = workStack.Stats().Peak
+	stats.Workers = workerStats
+
 	return MultipleRecipesResult{
-		Recipes:   recipes,
+		Recipes:   materializeRecipes(recipes, maxRecipes),
 		NodeCount: len(visited),
+		Frontier:  workStack.Stats(),
+		Stats:     stats,
 	}
 }
 
 // processWorkBatchAtomic memproses batch pekerjaan DFS dan menggunakan atomic counter
 // untuk melacak jumlah resep yang dihasilkan
-func processWorkBatchAtomic(batch []DFSWorkItem, revCombinations map[string][]Pair,
-	tierMap map[string]int, seenRecipes *sync.Map, localVisited map[string]bool,
-	target string, maxRecipes int, recipeCounter *int32) DFSProcessingResult {
-	
+func processWorkBatchAtomic(batch []DFSWorkItem, ds *Dataset, policy ValidityPolicy, seenRecipes *sync.Map, localVisited map[string]bool,
+	target string, maxRecipes int, recipeCounter *int32, c *searchCounters) DFSProcessingResult {
+
 	result := DFSProcessingResult{
-		NewRecipes:      make([]map[string]Element, 0),
+		NewRecipes:      make([]*RecipeChain, 0),
 		NewWorkItems:    make([]DFSWorkItem, 0),
 		VisitedElements: make(map[string]bool),
 	}
@@ -259,120 +293,111 @@ func processWorkBatchAtomic(batch []DFSWorkItem, revCombinations map[string][]Pa
 			break
 		}
 
+		result.Expanded++
+		c.expand()
+
 		element := item.Element
-		baseRecipes := item.BaseRecipes
+		baseRecipe := item.Recipe
 		exploredPairs := item.ExploredPairs
 
 		// Ambil semua pasangan valid untuk elemen ini
-		pairs := revCombinations[element]
-		validPairs := filterValidPairs(pairs, element, tierMap)
+		pairs := ds.RevCombinations[element]
+		validPairs := filterValidPairs(pairs, element, ds.TierMap, policy)
+
+		// Ambil resep asli untuk elemen ini
+		originalSources, _ := baseRecipe.Get(element)
+		originalPairKey := pairToString(originalSources.Source, originalSources.Partner)
 
-		// Untuk setiap resep dasar, coba variasi dengan pasangan valid
-		for _, baseRecipe := range baseRecipes {
+		// Coba setiap pasangan alternatif
+		for _, pair := range validPairs {
 			// Periksa apakah kita sudah mencapai batas resep
 			if maxRecipes > 0 && atomic.LoadInt32(recipeCounter) >= int32(maxRecipes) {
 				break
 			}
 
-			// Ambil resep asli untuk elemen ini
-			originalSources := baseRecipe[element]
-			originalPairKey := pairToString(originalSources.Source, originalSources.Partner)
+			c.examine(1)
 
-			// Coba setiap pasangan alternatif
-			for _, pair := range validPairs {
-				// Periksa apakah kita sudah mencapai batas resep
-				if maxRecipes > 0 && atomic.LoadInt32(recipeCounter) >= int32(maxRecipes) {
-					break
-				}
+			// Buat key untuk pasangan ini
+			pairKey := pairToString(pair.First, pair.Second)
 
-				// Buat key untuk pasangan ini
-				pairKey := pairToString(pair.First, pair.Second)
+			// Skip jika pasangan ini sama dengan yang asli atau sudah dieksplorasi
+			if pairKey == originalPairKey || exploredPairs[pairKey] {
+				continue
+			}
 
-				// Skip jika pasangan ini sama dengan yang asli atau sudah dieksplorasi
-				if pairKey == originalPairKey || exploredPairs[pairKey] {
-					continue
-				}
+			// Buat variasi resep dengan pasangan alternatif ini, tanpa nyalin resep dasarnya
+			variation := newRecipeOverlay(baseRecipe)
+			variation.Set(element, Element{Source: pair.First, Partner: pair.Second})
 
-				// Tandai pasangan ini sudah dieksplorasi
-				newExploredPairs := make(map[string]bool)
-				for k, v := range exploredPairs {
-					newExploredPairs[k] = v
+			// Pastikan semua bahan baru memiliki resep valid jika belum ada di resep kita
+			allValid := true
+			for _, ingredient := range []string{pair.First, pair.Second} {
+				if ds.IsBase(ingredient) {
+					continue // Elemen dasar selalu valid
 				}
-				newExploredPairs[pairKey] = true
-
-				// Buat variasi resep dengan pasangan alternatif ini
-				variation := copyRecipe(baseRecipe)
-				variation[element] = Element{Source: pair.First, Partner: pair.Second}
 
-				// Pastikan semua bahan baru memiliki resep valid jika belum ada di resep kita
-				allValid := true
-				for _, ingredient := range []string{pair.First, pair.Second} {
-					if isBaseElement(ingredient) {
-						continue // Elemen dasar selalu valid
+				// Jika kita belum punya resep untuk bahan ini, cari resep
+				if _, exists := variation.Get(ingredient); !exists {
+					ingredientRecipe := findIngredientRecipe(ingredient, ds, policy, localVisited, c)
+					if len(ingredientRecipe) == 0 {
+						allValid = false
+						break
 					}
 
-					// Jika kita belum punya resep untuk bahan ini, cari resep
-					if _, exists := variation[ingredient]; !exists {
-						ingredientRecipe := findIngredientRecipe(ingredient, make(map[Pair]string), revCombinations, tierMap, localVisited)
-						if len(ingredientRecipe) == 0 {
-							allValid = false
-							break
-						}
-
-						// Tambahkan resep bahan ke variasi kita
-						for elem, sources := range ingredientRecipe {
-							if _, exists := variation[elem]; !exists {
-								variation[elem] = sources
-								localVisited[elem] = true
-								result.VisitedElements[elem] = true
-							}
+					// Tambahkan resep bahan ke variasi kita
+					for elem, sources := range ingredientRecipe {
+						if _, exists := variation.Get(elem); !exists {
+							variation.Set(elem, sources)
+							localVisited[elem] = true
+							result.VisitedElements[elem] = true
 						}
 					}
 				}
+			}
 
-				if !allValid {
-					continue // Skip variasi ini jika tidak dapat dilengkapi
-				}
+			if !allValid {
+				continue // Skip variasi ini jika tidak dapat dilengkapi
+			}
 
-				// Pastikan variasi ini valid dengan memperhatikan constraint tier
-				valid, elementsVisited := repairRecipeAfterChange(element, variation, revCombinations, tierMap)
-				
-				for elem := range elementsVisited {
-					localVisited[elem] = true
-					result.VisitedElements[elem] = true
-				}
+			// Pastikan variasi ini valid dengan memperhatikan constraint tier
+			valid, elementsVisited := repairRecipeAfterChange(element, variation, ds, policy, c)
 
-				if !valid {
-					continue // Skip variasi ini jika tidak valid
-				}
+			for elem := range elementsVisited {
+				localVisited[elem] = true
+				result.VisitedElements[elem] = true
+			}
 
-				// Cek apakah ini resep unik
-				recipeStr := RecipeToString(variation, target)
-				if _, seen := seenRecipes.LoadOrStore(recipeStr, true); !seen {
-					// Tambahkan ke resep baru
-					result.NewRecipes = append(result.NewRecipes, variation)
-					
-					// Increment atomic counter
-					newCount := atomic.AddInt32(recipeCounter, 1)
-					
-					// Jika sudah mencapai batas, berhenti mencari lebih banyak
-					if maxRecipes > 0 && newCount >= int32(maxRecipes) {
-						break
-					}
+			if !valid {
+				continue // Skip variasi ini jika tidak valid
+			}
 
-					// Dalam DFS, kita terus memperdalam eksplorasi untuk resep baru ini
-					// Tambahkan semua elemen non-dasar di resep ini untuk eksplorasi lebih lanjut
-					for elem := range variation {
-						if !isBaseElement(elem) {
-							// Cari alternatif untuk elemen ini jika mungkin diubah
-							if len(filterValidPairs(revCombinations[elem], elem, tierMap)) > 1 {
-								result.NewWorkItems = append(result.NewWorkItems, DFSWorkItem{
-									Element:       elem,
-									BaseRecipes:   []map[string]Element{variation},
-									ExploredPairs: make(map[string]bool),
-								})
-							}
-						}
+			// Cek apakah ini resep unik
+			recipeStr := recipeFingerprint(variation, target)
+			if _, seen := seenRecipes.LoadOrStore(recipeStr, true); seen {
+				c.duplicate()
+			} else {
+				// Tambahkan ke resep baru
+				chain := variation.Commit()
+				result.NewRecipes = append(result.NewRecipes, chain)
+
+				// Increment atomic counter
+				newCount := atomic.AddInt32(recipeCounter, 1)
+
+				// Jika sudah mencapai batas, berhenti mencari lebih banyak
+				if maxRecipes > 0 && newCount >= int32(maxRecipes) {
+					break
+				}
+
+				// Dalam DFS, kita terus memperdalam eksplorasi untuk resep baru ini
+				// Tambahkan semua elemen non-dasar di resep ini untuk eksplorasi lebih lanjut
+				for _, elem := range reachableElements(chain, target) {
+					// Cari alternatif untuk elemen ini jika mungkin diubah
+					if len(filterValidPairs(ds.RevCombinations[elem], elem, ds.TierMap, policy)) > 1 {
+						result.NewWorkItems = append(result.NewWorkItems, DFSWorkItem{
+							Element:       elem,
+							Recipe:        chain,
+							ExploredPairs: make(map[string]bool),
+						})
 					}
 				}
 			}
@@ -380,4 +405,4 @@ func processWorkBatchAtomic(batch []DFSWorkItem, revCombinations map[string][]Pa
 	}
 
 	return result
-}
\ No newline at end of file
+}
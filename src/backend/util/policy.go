@@ -0,0 +1,184 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ValidityPolicy nentuin kombinasi mana yang boleh dipake pencarian.
+// Semua algoritma nanya ke policy ini, bukan hardcode aturan tier sendiri.
+type ValidityPolicy interface {
+	// Name nama policy, dipake buat milih policy dari request
+	Name() string
+	// Allows ngecek apakah pair boleh dipake buat bikin product
+	Allows(product string, pair Pair, tierMap map[string]int) bool
+}
+
+// Nama-nama policy bawaan
+const (
+	StrictTierPolicyName    = "strict-tier"
+	NonStrictTierPolicyName = "non-strict-tier"
+	AcyclicPolicyName       = "acyclic"
+)
+
+// StrictTierPolicy aturan asli: kedua bahan harus dari tier lebih rendah dari produk
+type StrictTierPolicy struct{}
+
+func (StrictTierPolicy) Name() string { return StrictTierPolicyName }
+
+func (StrictTierPolicy) Allows(product string, pair Pair, tierMap map[string]int) bool {
+	productTier := tierMap[product]
+	return tierMap[pair.First] < productTier && tierMap[pair.Second] < productTier
+}
+
+func (StrictTierPolicy) acyclicByConstruction() {}
+
+// acyclicByConstruction ditandain policy yang gak mungkin ngizinin siklus
+type acyclicByConstruction interface {
+	acyclicByConstruction()
+}
+
+// isAcyclicByConstruction true kalo semua resep dari policy ini pasti asiklik
+func isAcyclicByConstruction(policy ValidityPolicy) bool {
+	_, ok := policy.(acyclicByConstruction)
+	return ok
+}
+
+// NonStrictTierPolicy bahan boleh dari tier yang sama dengan produk.
+// Bisa bikin siklus antar elemen setier, jadi pencarian wajib deteksi siklus.
+type NonStrictTierPolicy struct{}
+
+func (NonStrictTierPolicy) Name() string { return NonStrictTierPolicyName }
+
+func (NonStrictTierPolicy) Allows(product string, pair Pair, tierMap map[string]int) bool {
+	if pair.First == product || pair.Second == product {
+		return false
+	}
+	productTier := tierMap[product]
+	return tierMap[pair.First] <= productTier && tierMap[pair.Second] <= productTier
+}
+
+// AcyclicPolicy ngabaikan tier sama sekali: semua kombinasi di data boleh dipake,
+// yang penting resep akhirnya gak siklik (dicek sama pencariannya)
+type AcyclicPolicy struct{}
+
+func (AcyclicPolicy) Name() string { return AcyclicPolicyName }
+
+func (AcyclicPolicy) Allows(product string, pair Pair, tierMap map[string]int) bool {
+	return pair.First != product && pair.Second != product
+}
+
+// CustomPolicy policy dari predicate bebas
+type CustomPolicy struct {
+	name      string
+	predicate func(product string, pair Pair, tierMap map[string]int) bool
+}
+
+// NewCustomPolicy bikin policy dari predicate. Kombinasi yang butuh produknya
+// sendiri tetap selalu ditolak.
+func NewCustomPolicy(name string, predicate func(product string, pair Pair, tierMap map[string]int) bool) CustomPolicy {
+	return CustomPolicy{name: name, predicate: predicate}
+}
+
+func (p CustomPolicy) Name() string { return p.name }
+
+func (p CustomPolicy) Allows(product string, pair Pair, tierMap map[string]int) bool {
+	if pair.First == product || pair.Second == product {
+		return false
+	}
+	return p.predicate(product, pair, tierMap)
+}
+
+// DefaultPolicy policy yang dipake kalo request gak milih apa-apa
+var DefaultPolicy ValidityPolicy = StrictTierPolicy{}
+
+var (
+	policiesMutex sync.RWMutex
+	policies      = map[string]ValidityPolicy{
+		StrictTierPolicyName:    StrictTierPolicy{},
+		NonStrictTierPolicyName: NonStrictTierPolicy{},
+		AcyclicPolicyName:       AcyclicPolicy{},
+	}
+)
+
+// RegisterPolicy daftarin policy (misalnya CustomPolicy) biar bisa dipilih per request
+func RegisterPolicy(policy ValidityPolicy) {
+	policiesMutex.Lock()
+	defer policiesMutex.Unlock()
+	policies[policy.Name()] = policy
+}
+
+// PolicyByName nyari policy terdaftar. Nama kosong berarti DefaultPolicy.
+func PolicyByName(name string) (ValidityPolicy, error) {
+	if name == "" {
+		return DefaultPolicy, nil
+	}
+
+	policiesMutex.RLock()
+	defer policiesMutex.RUnlock()
+	policy, exists := policies[name]
+	if !exists {
+		return nil, fmt.Errorf("unsupported validity policy %q", name)
+	}
+	return policy, nil
+}
+
+// PolicyNames daftar nama policy yang terdaftar, urut alfabet
+func PolicyNames() []string {
+	policiesMutex.RLock()
+	defer policiesMutex.RUnlock()
+
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DerivationLevels kedalaman minimal tiap elemen yang bisa dibikin menurut policy
+// (elemen dasar 0, elemen yang mustahil dibikin gak ada di map). Pasangan yang
+// bahannya punya level lebih kecil dari produknya dijamin gak bikin siklus, jadi
+// dipake buat ngarahin pencarian di policy yang gak asiklik dari sananya.
+func DerivationLevels(ds *Dataset, policy ValidityPolicy) map[string]int {
+	if levels, exists := ds.levels.Load(policy.Name()); exists {
+		return levels.(map[string]int)
+	}
+
+	levels := minimalScores(ds, policy, MetricDepth)
+	ds.levels.Store(policy.Name(), levels)
+	return levels
+}
+
+// recipeHasCycle ngecek apakah pohon resep target butuh suatu elemen buat bikin dirinya sendiri.
+// Aturan strict tier gak mungkin siklik, tapi policy lain bisa.
+func recipeHasCycle(recipe recipeReader, target string) bool {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int)
+
+	var visit func(elem string) bool
+	visit = func(elem string) bool {
+		if isLeaf(recipe, elem) {
+			return false
+		}
+		switch state[elem] {
+		case inProgress:
+			return true
+		case done:
+			return false
+		}
+
+		sources, _ := recipe.Get(elem)
+		state[elem] = inProgress
+		cyclic := visit(sources.Source) || visit(sources.Partner)
+		state[elem] = done
+		return cyclic
+	}
+
+	return visit(target)
+}
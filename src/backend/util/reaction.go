@@ -0,0 +1,152 @@
+package util
+
+import (
+	"sort"
+	"strings"
+)
+
+// Reaction resep umum (hyperedge): semua Ingredients digabung jadi semua Products.
+// Kombinasi Little Alchemy (dua bahan -> satu produk) cuma kasus khusus, dan tetap
+// disimpen di Combinations biar algoritma berbasis Pair gak berubah.
+type Reaction struct {
+	Ingredients []string `json:"ingredients"`
+	Products    []string `json:"products"`
+}
+
+// IsPair true kalo reaction ini kombinasi biasa: dua bahan jadi satu produk
+func (r Reaction) IsPair() bool {
+	return len(r.Ingredients) == 2 && len(r.Products) == 1
+}
+
+// Key identitas reaction tanpa mandang urutan bahan dan produk
+func (r Reaction) Key() string {
+	ingredients := append([]string(nil), r.Ingredients...)
+	products := append([]string(nil), r.Products...)
+	sort.Strings(ingredients)
+	sort.Strings(products)
+	return strings.Join(ingredients, "+") + "=" + strings.Join(products, "+")
+}
+
+// Byproducts produk reaction selain product
+func (r Reaction) Byproducts(product string) []string {
+	var others []string
+	for _, p := range r.Products {
+		if p != product {
+			others = append(others, p)
+		}
+	}
+	return others
+}
+
+// AddReaction nambahin reaction ke dataset. Kombinasi biasa masuk ke Combinations
+// (dua arah, kayak hasil scraper), sisanya ke Reactions. Harus dipanggil sebelum
+// dataset dipake pencarian.
+func (ds *Dataset) AddReaction(r Reaction) {
+	if r.IsPair() {
+		product := r.Products[0]
+		first, second := r.Ingredients[0], r.Ingredients[1]
+		for _, pair := range []Pair{{First: first, Second: second}, {First: second, Second: first}} {
+			if _, exists := ds.Combinations[pair]; exists {
+				continue
+			}
+			ds.Combinations[pair] = product
+			ds.RevCombinations[product] = append(ds.RevCombinations[product], pair)
+		}
+		return
+	}
+	ds.Reactions = append(ds.Reactions, r)
+}
+
+// HasReactions true kalo dataset punya resep yang gak bisa ditulis sebagai Pair,
+// jadi pencariannya harus pake algoritma reaction
+func (ds *Dataset) HasReactions() bool {
+	return len(ds.Reactions) > 0
+}
+
+// reactionIndex semua resep dataset dalam bentuk Reaction, diindeks per produk dan per bahan
+type reactionIndex struct {
+	all       []Reaction
+	producers map[string][]int
+	consumers map[string][]int
+}
+
+// reactionIndex dibikin sekali per dataset. Kombinasi biasa ikut dimasukin
+// (A+B dan B+A jadi satu reaction), urutannya deterministik.
+func (ds *Dataset) reactionIndex() *reactionIndex {
+	ds.reactionsOnce.Do(func() {
+		index := &reactionIndex{
+			producers: make(map[string][]int),
+			consumers: make(map[string][]int),
+		}
+		add := func(r Reaction) {
+			id := len(index.all)
+			index.all = append(index.all, r)
+			for _, product := range r.Products {
+				index.producers[product] = append(index.producers[product], id)
+			}
+			seen := make(map[string]bool, len(r.Ingredients))
+			for _, ingredient := range r.Ingredients {
+				if !seen[ingredient] {
+					seen[ingredient] = true
+					index.consumers[ingredient] = append(index.consumers[ingredient], id)
+				}
+			}
+		}
+
+		products := make([]string, 0, len(ds.RevCombinations))
+		for product := range ds.RevCombinations {
+			products = append(products, product)
+		}
+		sort.Strings(products)
+		for _, product := range products {
+			for _, pair := range uniquePairs(ds.RevCombinations[product]) {
+				add(Reaction{Ingredients: []string{pair.First, pair.Second}, Products: []string{product}})
+			}
+		}
+		for _, r := range ds.Reactions {
+			add(r)
+		}
+		ds.reactions = index
+	})
+	return ds.reactions
+}
+
+// ReactionsFor semua resep yang ngehasilin element, termasuk kombinasi biasa
+func (ds *Dataset) ReactionsFor(element string) []Reaction {
+	index := ds.reactionIndex()
+	reactions := make([]Reaction, 0, len(index.producers[element]))
+	for _, id := range index.producers[element] {
+		reactions = append(reactions, index.all[id])
+	}
+	return reactions
+}
+
+// allowsReaction ngecek reaction buat bikin product menurut policy. Kombinasi biasa
+// langsung ditanya ke policy; reaction lain dicek per bahan (Pair{bahan, bahan}),
+// yang buat policy bawaan artinya sama: tiap bahan harus lolos aturan tier-nya.
+func allowsReaction(policy ValidityPolicy, product string, r Reaction, tierMap map[string]int) bool {
+	if len(r.Ingredients) == 2 {
+		return policy.Allows(product, Pair{First: r.Ingredients[0], Second: r.Ingredients[1]}, tierMap)
+	}
+	if len(r.Ingredients) == 0 {
+		return false
+	}
+	for _, ingredient := range r.Ingredients {
+		if !policy.Allows(product, Pair{First: ingredient, Second: ingredient}, tierMap) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReactionRecipe ubah resep Pair jadi resep Reaction, misalnya buat BuildReactionTree
+func ReactionRecipe(recipe map[string]Element) map[string]Reaction {
+	result := make(map[string]Reaction, len(recipe))
+	for elem, sources := range recipe {
+		if sources.Source == "" {
+			continue
+		}
+		result[elem] = Reaction{Ingredients: []string{sources.Source, sources.Partner}, Products: []string{elem}}
+	}
+	return result
+}
@@ -0,0 +1,214 @@
+package util
+
+import "sort"
+
+// maxReactionExpansions batas pilihan reaction yang dicoba MultipleReactions
+const maxReactionExpansions = 500000
+
+// reactionLayers forward chaining berlapis dari elemen dasar. Reaction jalan begitu
+// semua bahannya udah bisa dibikin; produknya dapet level max(level bahan) + 1.
+// Karena diproses per lapis, level tiap elemen minimal dan bahan resep yang dipilih
+// (via) selalu punya level lebih kecil dari produknya, jadi gak mungkin siklik.
+func reactionLayers(ds *Dataset, policy ValidityPolicy) (map[string]int, map[string]int) {
+	index := ds.reactionIndex()
+	levels := make(map[string]int, len(ds.TierMap))
+	via := make(map[string]int) // Elemen -> id reaction yang pertama kali ngehasilin
+
+	missing := make([]int, len(index.all))
+	for id, r := range index.all {
+		missing[id] = len(distinct(r.Ingredients))
+	}
+
+	var layer []string
+	for _, base := range ds.BaseElements {
+		if _, exists := levels[base]; !exists {
+			levels[base] = 0
+			layer = append(layer, base)
+		}
+	}
+
+	for level := 0; len(layer) > 0; level++ {
+		// Urut biar reaction yang kepilih deterministik
+		sort.Strings(layer)
+		var fired []int
+		for _, elem := range layer {
+			for _, id := range index.consumers[elem] {
+				missing[id]--
+				if missing[id] == 0 {
+					fired = append(fired, id)
+				}
+			}
+		}
+		sort.Ints(fired)
+
+		var next []string
+		for _, id := range fired {
+			r := index.all[id]
+			for _, product := range r.Products {
+				if _, exists := levels[product]; exists {
+					continue
+				}
+				if !allowsReaction(policy, product, r, ds.TierMap) {
+					continue
+				}
+				levels[product] = level + 1
+				via[product] = id
+				next = append(next, product)
+			}
+		}
+		layer = next
+	}
+
+	return levels, via
+}
+
+// ReactionLevels kayak DerivationLevels, tapi ngitung semua resep dataset
+// termasuk reaction N bahan dan multi-produk
+func ReactionLevels(ds *Dataset, policy ValidityPolicy) map[string]int {
+	key := "reactions/" + policy.Name()
+	if levels, exists := ds.levels.Load(key); exists {
+		return levels.(map[string]int)
+	}
+
+	levels, _ := reactionLayers(ds, policy)
+	ds.levels.Store(key, levels)
+	return levels
+}
+
+// ShortestReactions nyari resep dengan kedalaman minimal buat target di dataset
+// yang punya reaction. Hasilnya cuma elemen yang beneran dibutuhin target; nil
+// kalo target gak bisa dibikin.
+func ShortestReactions(target string, ds *Dataset, policy ValidityPolicy) map[string]Reaction {
+	levels, via := reactionLayers(ds, policy)
+	if _, exists := levels[target]; !exists {
+		return nil
+	}
+
+	index := ds.reactionIndex()
+	recipe := make(map[string]Reaction)
+	stack := []string{target}
+	for len(stack) > 0 {
+		elem := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, done := recipe[elem]; done || ds.IsBase(elem) {
+			continue
+		}
+		r := index.all[via[elem]]
+		recipe[elem] = r
+		stack = append(stack, r.Ingredients...)
+	}
+	return recipe
+}
+
+// MultipleReactionsResult hasil MultipleReactions
+type MultipleReactionsResult struct {
+	Recipes   []map[string]Reaction // Resep yang valid, tiap elemen dibikin satu reaction
+	NodeCount int                   // Jumlah pilihan reaction yang dicoba
+	Stats     SearchStats           // Statistik eksplorasi pencarian
+}
+
+// MultipleReactions ngumpulin sampai maxRecipes resep berbeda buat target.
+// Elemen yang belum punya resep dipilih satu-satu (urutan tetap), lalu dicoba
+// semua reaction yang ngehasilin elemen itu. Bahan wajib bisa dibikin; di policy
+// yang gak asiklik dari sananya, bahan juga harus punya level lebih kecil dari
+// produknya biar resepnya gak siklik.
+func MultipleReactions(target string, ds *Dataset, policy ValidityPolicy, maxRecipes int) MultipleReactionsResult {
+	var result MultipleReactionsResult
+	c := newSearchCounters()
+	levels := ReactionLevels(ds, policy)
+	if _, exists := levels[target]; !exists || maxRecipes <= 0 {
+		result.Stats = c.finish()
+		return result
+	}
+	checkLevels := !isAcyclicByConstruction(policy)
+
+	// Kandidat reaction per elemen, dihitung sekali aja
+	candidates := make(map[string][]Reaction)
+	options := func(elem string) []Reaction {
+		if cached, exists := candidates[elem]; exists {
+			return cached
+		}
+		var valid []Reaction
+		for _, r := range ds.ReactionsFor(elem) {
+			if reactionUsable(r, elem, levels, checkLevels) && allowsReaction(policy, elem, r, ds.TierMap) {
+				valid = append(valid, r)
+			}
+		}
+		candidates[elem] = valid
+		return valid
+	}
+
+	recipe := make(map[string]Reaction)
+	var expand func(open []string) bool
+	expand = func(open []string) bool {
+		// Buang elemen yang udah beres
+		for len(open) > 0 && (ds.IsBase(open[0]) || hasReaction(recipe, open[0])) {
+			open = open[1:]
+		}
+		if len(open) == 0 {
+			found := make(map[string]Reaction, len(recipe))
+			for elem, r := range recipe {
+				found[elem] = r
+			}
+			result.Recipes = append(result.Recipes, found)
+			return len(result.Recipes) >= maxRecipes
+		}
+
+		elem := open[0]
+		c.expand()
+		c.observeFrontier(len(open))
+		for _, r := range options(elem) {
+			if result.NodeCount >= maxReactionExpansions {
+				return true
+			}
+			result.NodeCount++
+			c.examine(1)
+
+			recipe[elem] = r
+			next := make([]string, 0, len(open)-1+len(r.Ingredients))
+			next = append(next, open[1:]...)
+			next = append(next, r.Ingredients...)
+			stop := expand(next)
+			delete(recipe, elem)
+			if stop {
+				return true
+			}
+		}
+		return false
+	}
+	expand([]string{target})
+
+	result.Stats = c.finish()
+	return result
+}
+
+// reactionUsable ngecek semua bahan r bisa dibikin, dan kalo checkLevels,
+// levelnya lebih kecil dari product
+func reactionUsable(r Reaction, product string, levels map[string]int, checkLevels bool) bool {
+	productLevel := levels[product]
+	for _, ingredient := range r.Ingredients {
+		level, exists := levels[ingredient]
+		if !exists || (checkLevels && level >= productLevel) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasReaction(recipe map[string]Reaction, elem string) bool {
+	_, exists := recipe[elem]
+	return exists
+}
+
+// distinct isi slice tanpa duplikat, urutan dipertahanin
+func distinct(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	var out []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
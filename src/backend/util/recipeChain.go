@@ -0,0 +1,154 @@
+package util
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// maxChainDepth batas panjang rantai parent sebelum RecipeChain dipadatkan
+// jadi satu map, biar lookup gak makin lambat seiring variasi makin dalam
+const maxChainDepth = 32
+
+// RecipeChain representasi resep yang persisten (structurally shared).
+// Tiap variasi cuma nyimpen perubahan (diff) terhadap parent-nya, jadi
+// ribuan variasi di frontier bisa berbagi struktur yang sama tanpa
+// harus nyalin map[string]Element utuh kayak copyRecipe.
+type RecipeChain struct {
+	parent  *RecipeChain
+	entries map[string]Element // Diff terhadap parent (atau resep lengkap kalo root)
+	depth   int
+}
+
+// NewRecipeChain bikin root chain dari sebuah map resep
+func NewRecipeChain(recipe map[string]Element) *RecipeChain {
+	return &RecipeChain{entries: copyRecipe(recipe)}
+}
+
+// Get nyari resep sebuah elemen, mulai dari diff paling baru ke root
+func (c *RecipeChain) Get(elem string) (Element, bool) {
+	for node := c; node != nil; node = node.parent {
+		if sources, exists := node.entries[elem]; exists {
+			return sources, true
+		}
+	}
+	return Element{}, false
+}
+
+// Derive bikin variasi baru yang nunjuk ke chain ini sebagai parent
+func (c *RecipeChain) Derive(changes map[string]Element) *RecipeChain {
+	if len(changes) == 0 {
+		return c
+	}
+
+	// Kalo rantai udah kepanjangan, padatkan jadi root baru
+	if c.depth+1 >= maxChainDepth {
+		flat := c.Materialize()
+		for elem, sources := range changes {
+			flat[elem] = sources
+		}
+		return &RecipeChain{entries: flat}
+	}
+
+	return &RecipeChain{parent: c, entries: changes, depth: c.depth + 1}
+}
+
+// Materialize ngegabungin seluruh rantai jadi satu map resep biasa
+func (c *RecipeChain) Materialize() map[string]Element {
+	nodes := []*RecipeChain{}
+	for node := c; node != nil; node = node.parent {
+		nodes = append(nodes, node)
+	}
+
+	// Terapkan dari root ke diff terbaru supaya perubahan baru menimpa yang lama
+	result := make(map[string]Element)
+	for i := len(nodes) - 1; i >= 0; i-- {
+		for elem, sources := range nodes[i].entries {
+			result[elem] = sources
+		}
+	}
+	return result
+}
+
+// GobEncode nyimpen chain dalam bentuk map yang udah dimaterialisasi (dipake pas spill)
+func (c *RecipeChain) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c.Materialize()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode ngebaca chain hasil spill sebagai root baru
+func (c *RecipeChain) GobDecode(data []byte) error {
+	var entries map[string]Element
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entries); err != nil {
+		return err
+	}
+	c.parent = nil
+	c.entries = entries
+	c.depth = 0
+	return nil
+}
+
+// recipeReader abstraksi resep yang bisa dibaca, dipenuhi map biasa,
+// RecipeChain, maupun recipeOverlay
+type recipeReader interface {
+	Get(elem string) (Element, bool)
+}
+
+// recipeStore recipeReader yang juga bisa ditulis
+type recipeStore interface {
+	recipeReader
+	Set(elem string, sources Element)
+}
+
+// recipeMap adapter map resep biasa ke recipeStore
+type recipeMap map[string]Element
+
+func (m recipeMap) Get(elem string) (Element, bool) {
+	sources, exists := m[elem]
+	return sources, exists
+}
+
+func (m recipeMap) Set(elem string, sources Element) {
+	m[elem] = sources
+}
+
+// recipeOverlay nampung perubahan sementara di atas sebuah RecipeChain.
+// Variasi yang akhirnya ditolak gak pernah nyalin resep dasarnya.
+type recipeOverlay struct {
+	base    *RecipeChain
+	changes map[string]Element
+}
+
+func newRecipeOverlay(base *RecipeChain) *recipeOverlay {
+	return &recipeOverlay{base: base, changes: make(map[string]Element)}
+}
+
+func (o *recipeOverlay) Get(elem string) (Element, bool) {
+	if sources, exists := o.changes[elem]; exists {
+		return sources, true
+	}
+	return o.base.Get(elem)
+}
+
+func (o *recipeOverlay) Set(elem string, sources Element) {
+	o.changes[elem] = sources
+}
+
+// Commit ngubah overlay jadi RecipeChain baru yang berbagi struktur dengan base
+func (o *recipeOverlay) Commit() *RecipeChain {
+	return o.base.Derive(o.changes)
+}
+
+// materializeRecipes ngubah daftar chain jadi map resep, maksimal limit buah (0 = semua)
+func materializeRecipes(chains []*RecipeChain, limit int) []map[string]Element {
+	if limit > 0 && len(chains) > limit {
+		chains = chains[:limit]
+	}
+	recipes := make([]map[string]Element, 0, len(chains))
+	for _, chain := range chains {
+		recipes = append(recipes, chain.Materialize())
+	}
+	return recipes
+}
@@ -0,0 +1,124 @@
+package util
+
+import "sort"
+
+// RecipeStep satu langkah kombinasi di resep, bahannya udah dinormalisasi (A+B = B+A)
+type RecipeStep struct {
+	Element     string `json:"element"`
+	Ingredients string `json:"ingredients"`
+}
+
+// StepChange elemen yang ada di kedua resep tapi dibikin dari bahan yang beda
+type StepChange struct {
+	Element string `json:"element"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// RecipeDiff perbedaan dua resep untuk target yang sama
+type RecipeDiff struct {
+	Target         string       `json:"target"`
+	Identical      bool         `json:"identical"`
+	Added          []RecipeStep `json:"added"`          // Elemen yang cuma dibikin di resep B
+	Removed        []RecipeStep `json:"removed"`        // Elemen yang cuma dibikin di resep A
+	Changed        []StepChange `json:"changed"`        // Elemen di keduanya dengan bahan berbeda
+	SharedSubtrees []string     `json:"sharedSubtrees"` // Akar sub-tree yang identik di kedua resep
+	SharedSteps    int          `json:"sharedSteps"`    // Jumlah langkah di dalam sub-tree yang identik
+	StepsA         int          `json:"stepsA"`
+	StepsB         int          `json:"stepsB"`
+	StepDelta      int          `json:"stepDelta"` // StepsB - StepsA
+}
+
+// DiffRecipes ngebandingin dua resep untuk target yang sama.
+// Cuma elemen yang beneran dipake pohon target yang dibandingin,
+// sisa-sisa hasil pencarian yang gak kepake di-skip.
+func DiffRecipes(a, b map[string]Element, target string) RecipeDiff {
+	usedA := usedRecipe(a, target)
+	usedB := usedRecipe(b, target)
+
+	diff := RecipeDiff{
+		Target:         target,
+		Identical:      len(usedA) == len(usedB) && !isUniqueRecipe(usedA, []map[string]Element{usedB}),
+		Added:          []RecipeStep{},
+		Removed:        []RecipeStep{},
+		Changed:        []StepChange{},
+		SharedSubtrees: []string{},
+		StepsA:         len(usedA),
+		StepsB:         len(usedB),
+	}
+	diff.StepDelta = diff.StepsB - diff.StepsA
+
+	for elem, sources := range usedA {
+		other, exists := usedB[elem]
+		ingredients := NormalizeIngredients(sources.Source, sources.Partner)
+		if !exists {
+			diff.Removed = append(diff.Removed, RecipeStep{Element: elem, Ingredients: ingredients})
+			continue
+		}
+		if otherIngredients := NormalizeIngredients(other.Source, other.Partner); otherIngredients != ingredients {
+			diff.Changed = append(diff.Changed, StepChange{Element: elem, From: ingredients, To: otherIngredients})
+		}
+	}
+	for elem, sources := range usedB {
+		if _, exists := usedA[elem]; !exists {
+			diff.Added = append(diff.Added, RecipeStep{Element: elem, Ingredients: NormalizeIngredients(sources.Source, sources.Partner)})
+		}
+	}
+
+	// Sub-tree identik: resepnya sama dan kedua bahannya elemen dasar atau juga identik
+	shared := make(map[string]bool)
+	var isShared func(elem string) bool
+	isShared = func(elem string) bool {
+		sa, inA := usedA[elem]
+		sb, inB := usedB[elem]
+		if !inA && !inB {
+			return true // Gak dibikin di kedua resep, berarti elemen dasar
+		}
+		if value, exists := shared[elem]; exists {
+			return value
+		}
+		shared[elem] = false // Jaga-jaga kalo ada siklus
+		value := inA && inB &&
+			NormalizeIngredients(sa.Source, sa.Partner) == NormalizeIngredients(sb.Source, sb.Partner) &&
+			isShared(sa.Source) && isShared(sa.Partner)
+		shared[elem] = value
+		return value
+	}
+
+	// Akar sub-tree identik = elemen identik yang gak dipake elemen identik lain
+	usedByShared := make(map[string]bool)
+	for elem, sources := range usedA {
+		if isShared(elem) {
+			diff.SharedSteps++
+			usedByShared[sources.Source] = true
+			usedByShared[sources.Partner] = true
+		}
+	}
+	for elem := range usedA {
+		if isShared(elem) && !usedByShared[elem] {
+			diff.SharedSubtrees = append(diff.SharedSubtrees, elem)
+		}
+	}
+
+	sortSteps(diff.Added)
+	sortSteps(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Element < diff.Changed[j].Element })
+	sort.Strings(diff.SharedSubtrees)
+
+	return diff
+}
+
+// usedRecipe motong resep jadi cuma elemen yang dipake pohon target
+func usedRecipe(recipe map[string]Element, target string) map[string]Element {
+	used := make(map[string]Element)
+	for _, elem := range reachableElements(recipeMap(recipe), target) {
+		if sources, exists := recipe[elem]; exists {
+			used[elem] = sources
+		}
+	}
+	return used
+}
+
+func sortSteps(steps []RecipeStep) {
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Element < steps[j].Element })
+}
@@ -1,49 +1,71 @@
 package util
 
-import "slices"
-
 // RecipeToString menghasilkan representasi string unik dari sebuah resep
 // Berfungsi sebagai "fingerprint" resep untuk deteksi duplikat
 func RecipeToString(recipe map[string]Element, target string) string {
+	return recipeFingerprint(recipeMap(recipe), target)
+}
+
+// recipeFingerprint sama kayak RecipeToString tapi bisa jalan di atas
+// recipeReader apa aja (misalnya recipeOverlay) tanpa materialisasi map
+func recipeFingerprint(recipe recipeReader, target string) string {
 	// Track all elements we've seen so far
 	processed := make(map[string]bool)
 	var result string
-	
+
 	// Recursive function to build string representation
 	// Fungsi rekursif untuk membuat representasi string dari resep,
 	// mempertimbangkan struktur lengkap (bukan cuma ingredient top-level)
 	var processElement func(elem string)
 	processElement = func(elem string) {
-		if processed[elem] || isBaseElement(elem) {
+		if processed[elem] || isLeaf(recipe, elem) {
 			return
 		}
-		
+
 		processed[elem] = true
-		sources := recipe[elem]
-		
+		sources, _ := recipe.Get(elem)
+
 		// Normalize ingredient order
 		first, second := sources.Source, sources.Partner
 		if first > second {
 			first, second = second, first
 		}
-		
+
 		// Add this element's recipe to the string
 		result += elem + ":" + first + "+" + second + "|"
-		
+
 		// Process ingredients recursively
-		if !isBaseElement(first) {
-			processElement(first)
-		}
-		if !isBaseElement(second) {
-			processElement(second)
-		}
+		processElement(first)
+		processElement(second)
 	}
-	
+
 	// Start with the target
 	processElement(target)
 	return result
 }
 
+// reachableElements ngembaliin elemen yang beneran dibikin di pohon resep target (bukan daun).
+// Variasi di elemen lain gak bakal ngubah fingerprint, jadi gak perlu masuk frontier.
+func reachableElements(recipe recipeReader, target string) []string {
+	result := []string{}
+	processed := make(map[string]bool)
+	stack := []string{target}
+
+	for len(stack) > 0 {
+		elem := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if processed[elem] || isLeaf(recipe, elem) {
+			continue
+		}
+		processed[elem] = true
+		result = append(result, elem)
+
+		sources, _ := recipe.Get(elem)
+		stack = append(stack, sources.Source, sources.Partner)
+	}
+	return result
+}
+
 // NormalizeIngredients menormalkan urutan dua bahan sehingga A+B = B+A
 func NormalizeIngredients(a, b string) string {
 	if a > b {
@@ -52,230 +74,259 @@ func NormalizeIngredients(a, b string) string {
 	return a + "+" + b
 }
 
-
 // Helper function untuk mengecek apakah sebuah resep unik dibandingkan dengan daftar resep yang ada
 // Perlu memastikan bahwa kombinasi A+B dianggap sama dengan B+A
 func isUniqueRecipe(recipe map[string]Element, existingRecipes []map[string]Element) bool {
-  for _, existing := range existingRecipes {
-    // Perlu tracking elemen yang sudah dibandingkan
-    match := true
-    
-    // Bandingkan semua elemen di resep dengan resep yang sudah ada
-    for elem, sources := range recipe {
-      if existingSources, hasElem := existing[elem]; hasElem {
-        // Normalisasi urutan bahan untuk perbandingan yang adil
-        recipeFirst, recipeSecond := sources.Source, sources.Partner
-        existingFirst, existingSecond := existingSources.Source, existingSources.Partner
-        
-        // Urutkan bahan berdasarkan string agar A+B = B+A
-        if recipeFirst > recipeSecond {
-          recipeFirst, recipeSecond = recipeSecond, recipeFirst
-        }
-        if existingFirst > existingSecond {
-          existingFirst, existingSecond = existingSecond, existingFirst
-        }
-        
-        // Bandingkan dengan urutan yang sudah dinormalisasi
-        if recipeFirst != existingFirst || recipeSecond != existingSecond {
-          match = false
-          break
-        }
-      } else {
-        // Elemen tidak ada di resep existing
-        match = false
-        break
-      }
-    }
-    
-    // Pastikan kedua resep memiliki jumlah elemen yang sama
-    if match && len(recipe) == len(existing) {
-      return false // Ditemukan resep yang sama
-    }
-  }
-  
-  return true // Tidak ada resep yang sama
+	for _, existing := range existingRecipes {
+		// Perlu tracking elemen yang sudah dibandingkan
+		match := true
+
+		// Bandingkan semua elemen di resep dengan resep yang sudah ada
+		for elem, sources := range recipe {
+			if existingSources, hasElem := existing[elem]; hasElem {
+				// Normalisasi urutan bahan untuk perbandingan yang adil
+				recipeFirst, recipeSecond := sources.Source, sources.Partner
+				existingFirst, existingSecond := existingSources.Source, existingSources.Partner
+
+				// Urutkan bahan berdasarkan string agar A+B = B+A
+				if recipeFirst > recipeSecond {
+					recipeFirst, recipeSecond = recipeSecond, recipeFirst
+				}
+				if existingFirst > existingSecond {
+					existingFirst, existingSecond = existingSecond, existingFirst
+				}
+
+				// Bandingkan dengan urutan yang sudah dinormalisasi
+				if recipeFirst != existingFirst || recipeSecond != existingSecond {
+					match = false
+					break
+				}
+			} else {
+				// Elemen tidak ada di resep existing
+				match = false
+				break
+			}
+		}
+
+		// Pastikan kedua resep memiliki jumlah elemen yang sama
+		if match && len(recipe) == len(existing) {
+			return false // Ditemukan resep yang sama
+		}
+	}
+
+	return true // Tidak ada resep yang sama
 }
 
 // findIngredientRecipe mencari resep valid untuk suatu ingredient
-// Fungsi ini memastikan kita bisa membuat ingredient dengan aturan tiering
-func findIngredientRecipe(ingredient string, combinations map[Pair]string, revCombinations map[string][]Pair, tierMap map[string]int, visited map[string]bool) map[string]Element {
-  // Kalo udah elemen dasar, gak perlu resep
-  if isBaseElement(ingredient) {
-    return map[string]Element{}
-  }
-  
-  // Cari semua pasangan valid berdasarkan tier
-  pairs := revCombinations[ingredient]
-  result := make(map[string]Element)
-  
-  // Coba setiap pasangan valid
-  for _, pair := range pairs {
-    // Cek validitas tier
-    productTier := tierMap[ingredient]
-    if tierMap[pair.First] >= productTier || tierMap[pair.Second] >= productTier {
-      continue
-    }
-    
-    // Catat resep untuk ingredient ini
-    result[ingredient] = Element{Source: pair.First, Partner: pair.Second}
-    
-    // Coba cari resep untuk tiap bahan
-    validRecipe := true
-    
-    for _, source := range []string{pair.First, pair.Second} {
-      if isBaseElement(source) {
-        continue
-      }
-      
-      // Cari resep untuk bahan secara rekursif
-      sourceRecipe := findIngredientRecipe(source, combinations, revCombinations, tierMap, visited)
-      if len(sourceRecipe) == 0 {
-        validRecipe = false
-        break
-      }
-      
-      // Tambahkan resep bahan ke hasil
-      for elem, elemSources := range sourceRecipe {
-        result[elem] = elemSources
-      }
-    }
-    
-    if validRecipe {
-      // Update elemen yang udah dikunjungi
-      for elem := range result {
-        visited[elem] = true
-      }
-      return result
-    }
-  }
-  
-  return map[string]Element{}
-}
+// Fungsi ini memastikan kita bisa membuat ingredient dengan aturan dari policy
+func findIngredientRecipe(ingredient string, ds *Dataset, policy ValidityPolicy, visited map[string]bool, c *searchCounters) map[string]Element {
+	c.subSearch()
+
+	// Elemen di jalur rekursi saat ini (deteksi siklus) dan elemen yang udah pasti gagal
+	path := make(map[string]bool)
+	failed := make(map[string]bool)
+
+	// Sama kayak ShortestDfs: policy yang bisa siklik diarahin pake level derivasi
+	var levels map[string]int
+	if !isAcyclicByConstruction(policy) {
+		levels = DerivationLevels(ds, policy)
+	}
+
+	var find func(ingredient string) map[string]Element
+	find = func(ingredient string) map[string]Element {
+		// Kalo udah elemen dasar, gak perlu resep
+		if ds.IsBase(ingredient) {
+			return map[string]Element{}
+		}
+
+		// Policy selain strict tier bisa bikin siklus, jangan muter-muter
+		if path[ingredient] || failed[ingredient] {
+			return map[string]Element{}
+		}
+		path[ingredient] = true
+		defer delete(path, ingredient)
+		c.expand()
+
+		// Cari semua pasangan valid berdasarkan policy
+		pairs := filterValidPairs(ds.RevCombinations[ingredient], ingredient, ds.TierMap, policy)
+		if levels != nil {
+			pairs = filterByLevel(pairs, ingredient, levels)
+		}
+		result := make(map[string]Element)
+
+		// Coba setiap pasangan valid
+		for _, pair := range pairs {
+			c.examine(1)
+
+			// Catat resep untuk ingredient ini
+			result[ingredient] = Element{Source: pair.First, Partner: pair.Second}
+
+			// Coba cari resep untuk tiap bahan
+			validRecipe := true
 
+			for _, source := range []string{pair.First, pair.Second} {
+				if ds.IsBase(source) {
+					continue
+				}
+
+				// Cari resep untuk bahan secara rekursif
+				sourceRecipe := find(source)
+				if len(sourceRecipe) == 0 {
+					validRecipe = false
+					break
+				}
+
+				// Tambahkan resep bahan ke hasil
+				for elem, elemSources := range sourceRecipe {
+					result[elem] = elemSources
+				}
+			}
+
+			if validRecipe && !recipeHasCycle(recipeMap(result), ingredient) {
+				// Update elemen yang udah dikunjungi
+				for elem := range result {
+					visited[elem] = true
+				}
+				return result
+			}
+		}
+
+		failed[ingredient] = true
+		return map[string]Element{}
+	}
+
+	return find(ingredient)
+}
 
 // findElementsWithAlternatives nyari elemen di pohon resep yang punya banyak resep valid
 // Ngereturn elemen berurutan dari posisinya di pohon resep (dari daun ke akar)
-func findElementsWithAlternatives(target string, recipe map[string]Element, revCombinations map[string][]Pair, tierMap map[string]int) []string {
-  result := []string{}
-  processed := make(map[string]bool)
-  
-  // Fungsi rekursif buat jelajahin pohon resep
-  var explore func(element string)
-  explore = func(element string) {
-    // Skip kalo udah diproses atau elemen dasar
-    if processed[element] || isBaseElement(element) {
-      return
-    }
-    processed[element] = true
-    
-    // Cek apakah elemen ini punya resep alternatif
-    pairs := revCombinations[element]
-    validPairs := filterValidPairs(pairs, element, tierMap)
-    if len(validPairs) > 1 {
-      result = append(result, element)
-    }
-    
-    // Jelajahi bahan-bahannya
-    elemRecipe, exists := recipe[element]
-    if exists && elemRecipe.Source != "" && elemRecipe.Partner != "" {
-      explore(elemRecipe.Source)
-      explore(elemRecipe.Partner)
-    }
-  }
-  
-  // Mulai jelajah dari target
-  explore(target)
-  
-  return result
+func findElementsWithAlternatives(target string, recipe map[string]Element, ds *Dataset, policy ValidityPolicy) []string {
+	result := []string{}
+	processed := make(map[string]bool)
+
+	// Fungsi rekursif buat jelajahin pohon resep
+	var explore func(element string)
+	explore = func(element string) {
+		// Skip kalo udah diproses atau elemen dasar
+		if processed[element] || ds.IsBase(element) {
+			return
+		}
+		processed[element] = true
+
+		// Cek apakah elemen ini punya resep alternatif
+		pairs := ds.RevCombinations[element]
+		validPairs := filterValidPairs(pairs, element, ds.TierMap, policy)
+		if len(validPairs) > 1 {
+			result = append(result, element)
+		}
+
+		// Jelajahi bahan-bahannya
+		elemRecipe, exists := recipe[element]
+		if exists && elemRecipe.Source != "" && elemRecipe.Partner != "" {
+			explore(elemRecipe.Source)
+			explore(elemRecipe.Partner)
+		}
+	}
+
+	// Mulai jelajah dari target
+	explore(target)
+
+	return result
 }
 
 // repairRecipeAfterChange mastiin resep masih valid setelah ganti resep satu elemen
 // Ngereturn apakah perbaikan berhasil dan map elemen yang dikunjungi selama perbaikan
-func repairRecipeAfterChange(changedElement string, recipe map[string]Element, revCombinations map[string][]Pair, tierMap map[string]int) (bool, map[string]bool) {
-  visited := make(map[string]bool)
-  
-  // Tandai elemen dasar sebagai visited
-  for _, elem := range BaseElements {
-    visited[elem] = true
-  }
-  
-  // Kumpulin semua elemen yang perlu dicek/diperbaiki
-  // Mulai dari bahan-bahan elemen yang diubah
-  elementsToCheck := []string{}
-  changedRecipe := recipe[changedElement]
-  
-  // Tambah bahan-bahan elemen yang diubah ke list cek
-  if !isBaseElement(changedRecipe.Source) {
-    elementsToCheck = append(elementsToCheck, changedRecipe.Source)
-  }
-  if !isBaseElement(changedRecipe.Partner) {
-    elementsToCheck = append(elementsToCheck, changedRecipe.Partner)
-  }
-  
-  // Buat tiap elemen yang mau dicek
-  for len(elementsToCheck) > 0 {
-    // Ambil elemen berikutnya
-    element := elementsToCheck[0]
-    elementsToCheck = elementsToCheck[1:]
-    
-    // Skip kalo udah diproses
-    if visited[element] {
-      continue
-    }
-    visited[element] = true
-    
-    // Cek apakah elemen ini punya resep valid di kondisi saat ini
-    // Kalo gak, cari pake ShortestDfs
-    if _, exists := recipe[element]; !exists || recipe[element].Source == "" || recipe[element].Partner == "" {
-      // Jalanin ShortestDfs cuma buat elemen ini
-      miniResult := ShortestDfs(element, revCombinations, tierMap)
-      
-      // Kalo gak nemu resep, perbaikan gagal
-      if len(miniResult) == 0 || miniResult[element].Source == "" || miniResult[element].Partner == "" {
-        return false, visited
-      }
-      
-      // Tambahin resep ini ke map resep kita
-      recipe[element] = miniResult[element]
-      
-      // Tambahin semua elemen dari miniResult ke map resep kita
-      for elem, r := range miniResult {
-        if elem != element {
-          recipe[elem] = r
-          visited[elem] = true
-        }
-      }
-    }
-    
-    // Tambahin bahan-bahan elemen ini ke list cek kalo bukan elemen dasar
-    elemRecipe := recipe[element]
-    if !isBaseElement(elemRecipe.Source) {
-      elementsToCheck = append(elementsToCheck, elemRecipe.Source)
-    }
-    if !isBaseElement(elemRecipe.Partner) {
-      elementsToCheck = append(elementsToCheck, elemRecipe.Partner)
-    }
-  }
-  
-  return true, visited
+func repairRecipeAfterChange(changedElement string, recipe recipeStore, ds *Dataset, policy ValidityPolicy, c *searchCounters) (bool, map[string]bool) {
+	visited := make(map[string]bool)
+
+	// Tandai elemen dasar sebagai visited
+	for _, elem := range ds.BaseElements {
+		visited[elem] = true
+	}
+
+	// Kumpulin semua elemen yang perlu dicek/diperbaiki
+	// Mulai dari bahan-bahan elemen yang diubah
+	elementsToCheck := []string{}
+	changedRecipe, _ := recipe.Get(changedElement)
+
+	// Tambah bahan-bahan elemen yang diubah ke list cek
+	if !ds.IsBase(changedRecipe.Source) {
+		elementsToCheck = append(elementsToCheck, changedRecipe.Source)
+	}
+	if !ds.IsBase(changedRecipe.Partner) {
+		elementsToCheck = append(elementsToCheck, changedRecipe.Partner)
+	}
+
+	// Buat tiap elemen yang mau dicek
+	for len(elementsToCheck) > 0 {
+		// Ambil elemen berikutnya
+		element := elementsToCheck[0]
+		elementsToCheck = elementsToCheck[1:]
+
+		// Skip kalo udah diproses
+		if visited[element] {
+			continue
+		}
+		visited[element] = true
+
+		// Cek apakah elemen ini punya resep valid di kondisi saat ini
+		// Kalo gak, cari pake ShortestDfs
+		if current, exists := recipe.Get(element); !exists || current.Source == "" || current.Partner == "" {
+			// Jalanin ShortestDfs cuma buat elemen ini
+			c.subSearch()
+			miniResult, found := shortestDfs(element, ds, policy, c)
+
+			// Kalo gak nemu resep, perbaikan gagal
+			if !found || miniResult[element].Source == "" || miniResult[element].Partner == "" {
+				return false, visited
+			}
+
+			// Tambahin resep ini ke map resep kita
+			recipe.Set(element, miniResult[element])
+
+			// Tambahin semua elemen dari miniResult ke map resep kita
+			for elem, r := range miniResult {
+				if elem != element {
+					recipe.Set(elem, r)
+					visited[elem] = true
+				}
+			}
+		}
+
+		// Tambahin bahan-bahan elemen ini ke list cek kalo bukan elemen dasar
+		elemRecipe, _ := recipe.Get(element)
+		if !ds.IsBase(elemRecipe.Source) {
+			elementsToCheck = append(elementsToCheck, elemRecipe.Source)
+		}
+		if !ds.IsBase(elemRecipe.Partner) {
+			elementsToCheck = append(elementsToCheck, elemRecipe.Partner)
+		}
+	}
+
+	// Bahan baru bisa aja butuh elemen yang diubah (kalo policy-nya bukan strict tier)
+	return !recipeHasCycle(recipe, changedElement), visited
 }
 
 // copyRecipe bikin salinan dalam dari map resep
 func copyRecipe(original map[string]Element) map[string]Element {
-  copy := make(map[string]Element)
-  for k, v := range original {
-    copy[k] = v
-  }
-  return copy
+	copy := make(map[string]Element)
+	for k, v := range original {
+		copy[k] = v
+	}
+	return copy
 }
 
-// Helper function to check if an element is a base element
-func isBaseElement(element string) bool {
-  return slices.Contains(BaseElements, element)
+// isLeaf ngecek apakah element daun di pohon resep. Algoritma gak pernah nyatet
+// resep buat elemen dasar (paling banter resep kosong), jadi ini berlaku di dataset apa aja.
+func isLeaf(recipe recipeReader, element string) bool {
+	sources, exists := recipe.Get(element)
+	return !exists || sources.Source == ""
 }
 
 // MultipleRecipesResult buat nyimpen hasil dari MultipleRecipesDfs
 type MultipleRecipesResult struct {
-  Recipes   []map[string]Element // Kumpulan resep yang valid
-  NodeCount int                  // Jumlah node/elemen yang dikunjungi
-}
\ No newline at end of file
+	Recipes   []map[string]Element // Kumpulan resep yang valid
+	NodeCount int                  // Jumlah node/elemen yang dikunjungi
+	Frontier  FrontierStats        // Statistik frontier (peak, prune, spill)
+	Stats     SearchStats          // Statistik eksplorasi pencarian
+}
@@ -0,0 +1,230 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Searcher satu algoritma pencarian resep yang bisa dipilih lewat field algoritma.
+// Server cuma manggil Search, jadi nambah algoritma cukup RegisterSearcher.
+type Searcher interface {
+	// Info nama, kemampuan, dan opsi algoritma, dipake /api/algorithms
+	Info() SearcherInfo
+	// Search nyari resep buat target. Algoritma yang cuma nyari satu resep
+	// ngembaliin paling banyak satu resep di Recipes.
+	Search(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions) MultipleRecipesResult
+}
+
+// TraceSearcher Searcher yang juga bisa nyatet langkah pencariannya (?trace=1).
+// Varian trace selalu nyari satu resep; found false kalo target gak bisa dibikin.
+type TraceSearcher interface {
+	Searcher
+	SearchTrace(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions, trace *SearchTrace) (recipe map[string]Element, found bool, stats SearchStats)
+}
+
+// SearcherInfo deskripsi algoritma buat frontend
+type SearcherInfo struct {
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	Capabilities SearcherCapabilities `json:"capabilities"`
+	Options      []OptionSpec         `json:"options"`
+}
+
+// SearcherCapabilities apa aja yang dijamin algoritma
+type SearcherCapabilities struct {
+	Multiple      bool `json:"multiple"`      // Bisa ngasih lebih dari satu resep (maksimalResep dipake)
+	Optimal       bool `json:"optimal"`       // Resep pertama pasti kedalamannya minimal
+	Deterministic bool `json:"deterministic"` // Input sama, hasil sama
+	Constraints   bool `json:"constraints"`   // Ngikutin batas memori frontier (maxFrontier, overflow)
+	Trace         bool `json:"trace"`         // Bisa dijalanin pake ?trace=1 (lihat TraceSearcher)
+}
+
+// OptionSpec satu field request yang khusus dibaca algoritma ini
+type OptionSpec struct {
+	Name        string   `json:"name"` // Nama field di JSON request
+	Type        string   `json:"type"` // "int", "string", atau "bool"
+	Default     any      `json:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description"`
+}
+
+// SearchOptions opsi dari request yang diterusin ke Searcher
+type SearchOptions struct {
+	MaxRecipes int
+	Workers    int // <= 0 berarti runtime.NumCPU()
+	Budget     MemoryBudget
+	BeamWidth  int
+	Context    context.Context // Pembatalan/timeout, nil berarti gak bisa dibatalin (cuma algoritma Multiple*)
+}
+
+// context Context dari opsi, context.Background() kalo kosong
+func (opts SearchOptions) context() context.Context {
+	if opts.Context == nil {
+		return context.Background()
+	}
+	return opts.Context
+}
+
+// Opsi yang dipake algoritma Multiple*
+var frontierOptions = []OptionSpec{
+	{Name: "maxFrontier", Type: "int", Description: "maximum queued variations kept in memory"},
+	{Name: "overflow", Type: "string", Default: string(SpillOverflow), Enum: []string{string(SpillOverflow), string(PruneOverflow)},
+		Description: "what to do with variations beyond maxFrontier"},
+}
+
+// FuncSearcher Searcher dari sebuah fungsi, kayak CustomPolicy buat policy
+type FuncSearcher struct {
+	info SearcherInfo
+	run  func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions) MultipleRecipesResult
+}
+
+// NewFuncSearcher bikin Searcher dari info dan fungsi pencariannya
+func NewFuncSearcher(info SearcherInfo, run func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions) MultipleRecipesResult) FuncSearcher {
+	if info.Options == nil {
+		info.Options = []OptionSpec{}
+	}
+	return FuncSearcher{info: info, run: run}
+}
+
+func (s FuncSearcher) Info() SearcherInfo { return s.info }
+
+func (s FuncSearcher) Search(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions) MultipleRecipesResult {
+	return s.run(target, ds, policy, opts)
+}
+
+// traceFunc varian trace sebuah FuncSearcher
+type traceFunc func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions, trace *SearchTrace) (map[string]Element, bool, SearchStats)
+
+// TracingFuncSearcher FuncSearcher yang juga TraceSearcher
+type TracingFuncSearcher struct {
+	FuncSearcher
+	trace traceFunc
+}
+
+// WithTrace nambahin varian trace ke FuncSearcher, Capabilities.Trace ikut diisi
+func (s FuncSearcher) WithTrace(trace traceFunc) TracingFuncSearcher {
+	s.info.Capabilities.Trace = true
+	return TracingFuncSearcher{FuncSearcher: s, trace: trace}
+}
+
+func (s TracingFuncSearcher) SearchTrace(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions, trace *SearchTrace) (map[string]Element, bool, SearchStats) {
+	return s.trace(target, ds, policy, opts, trace)
+}
+
+// traceWith bikin traceFunc dari varian *Trace yang gak butuh SearchOptions
+func traceWith(run func(string, *Dataset, ValidityPolicy, *SearchTrace) (map[string]Element, bool, SearchStats)) traceFunc {
+	return func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions, trace *SearchTrace) (map[string]Element, bool, SearchStats) {
+		return run(target, ds, policy, trace)
+	}
+}
+
+// singleRecipeResult bungkus hasil algoritma yang cuma nyari satu resep
+func singleRecipeResult(recipe map[string]Element, stats SearchStats) MultipleRecipesResult {
+	result := MultipleRecipesResult{
+		Recipes:   []map[string]Element{},
+		NodeCount: int(stats.NodesExpanded),
+		Stats:     stats,
+	}
+	if len(recipe) > 0 {
+		result.Recipes = append(result.Recipes, recipe)
+	}
+	return result
+}
+
+// DefaultSearcherName algoritma yang dipilih frontend kalo user belum milih
+const DefaultSearcherName = "BFS"
+
+var (
+	searchersMutex sync.RWMutex
+	searchers      = map[string]Searcher{}
+	searcherOrder  []string // Urutan daftar, biar dropdown frontend stabil
+)
+
+func init() {
+	RegisterSearcher(NewFuncSearcher(SearcherInfo{
+		Name:         "BFS",
+		Description:  "parallel breadth-first search over recipe variations",
+		Capabilities: SearcherCapabilities{Multiple: true, Constraints: true},
+		Options:      frontierOptions,
+	}, func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions) MultipleRecipesResult {
+		return MultipleBfs(opts.context(), target, ds, policy, opts.MaxRecipes, opts.Workers, opts.Budget)
+	}).WithTrace(traceWith(ShortestBfsTrace)))
+	RegisterSearcher(NewFuncSearcher(SearcherInfo{
+		Name:         "DFS",
+		Description:  "parallel depth-first search over recipe variations",
+		Capabilities: SearcherCapabilities{Multiple: true, Constraints: true},
+		Options:      frontierOptions,
+	}, func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions) MultipleRecipesResult {
+		return MultipleDfs(opts.context(), target, ds, policy, opts.MaxRecipes, opts.Workers, opts.Budget)
+	}).WithTrace(traceWith(ShortestDfsTrace)))
+	RegisterSearcher(NewFuncSearcher(SearcherInfo{
+		Name:         "Bi-BFS",
+		Description:  "bidirectional search; the first recipe has minimal depth, the rest are variations of it",
+		Capabilities: SearcherCapabilities{Multiple: true, Optimal: true, Constraints: true},
+		Options:      frontierOptions,
+	}, func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions) MultipleRecipesResult {
+		return MultipleBidirectional(opts.context(), target, ds, policy, opts.MaxRecipes, opts.Workers, opts.Budget)
+	}).WithTrace(traceWith(ShortestBidirectionalTrace)))
+	RegisterSearcher(NewFuncSearcher(SearcherInfo{
+		Name:         "IDDFS",
+		Description:  "iterative deepening DFS; one minimal-depth recipe with memory proportional to the element count",
+		Capabilities: SearcherCapabilities{Optimal: true, Deterministic: true},
+	}, func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions) MultipleRecipesResult {
+		return singleRecipeResult(ShortestIddfsWithStats(target, ds, policy))
+	}).WithTrace(traceWith(ShortestIddfsTrace)))
+	RegisterSearcher(NewFuncSearcher(SearcherInfo{
+		Name:         "Beam",
+		Description:  "beam search guided by tiers; one fast approximate recipe",
+		Capabilities: SearcherCapabilities{Deterministic: true},
+		Options: []OptionSpec{
+			{Name: "beamWidth", Type: "int", Default: DefaultBeamWidth, Description: "partial recipes kept per step; wider is slower but closer to optimal"},
+		},
+	}, func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions) MultipleRecipesResult {
+		return singleRecipeResult(BeamSearchWithStats(target, ds, policy, opts.BeamWidth))
+	}).WithTrace(func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions, trace *SearchTrace) (map[string]Element, bool, SearchStats) {
+		return BeamSearchTrace(target, ds, policy, opts.BeamWidth, trace)
+	}))
+	RegisterSearcher(NewFuncSearcher(SearcherInfo{
+		Name:         "A*",
+		Description:  "AO* search with a tier heuristic; one minimal-depth recipe",
+		Capabilities: SearcherCapabilities{Optimal: true, Deterministic: true},
+	}, func(target string, ds *Dataset, policy ValidityPolicy, opts SearchOptions) MultipleRecipesResult {
+		return singleRecipeResult(ShortestAStarWithStats(target, ds, policy))
+	}).WithTrace(traceWith(ShortestAStarTrace)))
+}
+
+// RegisterSearcher daftarin algoritma biar bisa dipilih per request.
+// Nama yang udah ada ditimpa.
+func RegisterSearcher(searcher Searcher) {
+	searchersMutex.Lock()
+	defer searchersMutex.Unlock()
+	name := searcher.Info().Name
+	if _, exists := searchers[name]; !exists {
+		searcherOrder = append(searcherOrder, name)
+	}
+	searchers[name] = searcher
+}
+
+// SearcherByName nyari algoritma terdaftar
+func SearcherByName(name string) (Searcher, error) {
+	searchersMutex.RLock()
+	defer searchersMutex.RUnlock()
+	searcher, exists := searchers[name]
+	if !exists {
+		return nil, fmt.Errorf("unsupported algorithm %q", name)
+	}
+	return searcher, nil
+}
+
+// SearcherInfos info semua algoritma terdaftar, urut didaftarin
+func SearcherInfos() []SearcherInfo {
+	searchersMutex.RLock()
+	defer searchersMutex.RUnlock()
+
+	infos := make([]SearcherInfo, 0, len(searcherOrder))
+	for _, name := range searcherOrder {
+		infos = append(infos, searchers[name].Info())
+	}
+	return infos
+}
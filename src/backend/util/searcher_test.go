@@ -0,0 +1,35 @@
+package util_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/util"
+)
+
+// Pencarian Multiple* tanpa batas resep harus berhenti begitu context-nya dibatalin.
+// Ninja punya ribuan resep, tanpa pembatalan makan waktu jauh lebih lama dari 5 detik.
+func TestSearchersStopOnCancel(t *testing.T) {
+	ds := loadRealDataset(t)
+	for _, info := range util.SearcherInfos() {
+		if !info.Capabilities.Multiple {
+			continue
+		}
+		searcher, err := util.SearcherByName(info.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		start := time.Now()
+		result := searcher.Search("Ninja", ds, util.StrictTierPolicy{}, util.SearchOptions{Workers: 2, Context: ctx})
+		elapsed := time.Since(start)
+		cancel()
+		if elapsed > 5*time.Second {
+			t.Errorf("%s: search took %v after a 200ms timeout", info.Name, elapsed)
+		}
+		if len(result.Recipes) == 0 {
+			t.Errorf("%s: cancelled search dropped the recipes it already found", info.Name)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package util
+
+// ShortestAStar nyari resep dengan kedalaman minimal pake A* versi graf AND/OR
+// (AO*). Tiap elemen punya perkiraan kedalaman f yang awalnya dari tierHeuristic;
+// tiap langkah satu ujung resep terbaik sementara dibuka, terus f dihitung ulang ke
+// atas sampai target. Begitu resep terbaik udah nyampe elemen dasar semua, hasilnya
+// pasti minimal karena heuristiknya gak pernah ngelebihin kedalaman sebenarnya.
+func ShortestAStar(target string, ds *Dataset, policy ValidityPolicy) map[string]Element {
+	return shortestAStar(target, ds, policy, nil)
+}
+
+// ShortestAStarWithStats ShortestAStar beserta statistik pencariannya
+func ShortestAStarWithStats(target string, ds *Dataset, policy ValidityPolicy) (map[string]Element, SearchStats) {
+	c := newSearchCounters()
+	recipe := shortestAStar(target, ds, policy, c)
+	return recipe, c.finish()
+}
+
+// aStarNode status satu elemen di AO*
+type aStarNode struct {
+	f        int    // Batas bawah kedalaman (pasti kalo solved)
+	pairs    []Pair // Pasangan valid, diisi waktu dibuka
+	best     Pair   // Pasangan dengan f terkecil
+	expanded bool
+	solved   bool // Resep lewat best udah nyampe elemen dasar semua
+}
+
+func shortestAStar(target string, ds *Dataset, policy ValidityPolicy, c *searchCounters) map[string]Element {
+	result := make(map[string]Element)
+	if _, exists := ds.RevCombinations[target]; !exists || ds.IsBase(target) {
+		return result
+	}
+
+	// Strict tier asiklik dari sananya. Policy lain diarahin pake level derivasi
+	// kayak ShortestDfs, biar perhitungan ulang f gak muter di siklus.
+	var levels map[string]int
+	if !isAcyclicByConstruction(policy) {
+		levels = DerivationLevels(ds, policy)
+	}
+
+	heuristic := tierHeuristic(ds)
+	nodes := make(map[string]*aStarNode)
+	parents := make(map[string][]string) // Bahan -> elemen terbuka yang pasangannya make bahan itu
+	node := func(elem string) *aStarNode {
+		if n, exists := nodes[elem]; exists {
+			return n
+		}
+		n := &aStarNode{f: heuristic[elem], solved: ds.IsBase(elem)}
+		if _, known := heuristic[elem]; !known && !n.solved {
+			n.f = unreachableScore
+		}
+		nodes[elem] = n
+		return n
+	}
+
+	// revise ngitung ulang f, best dan solved elem dari pasangan-pasangannya
+	revise := func(elem string) bool {
+		n := nodes[elem]
+		f, solved := unreachableScore, false
+		var best Pair
+		for _, pair := range n.pairs {
+			value := combineScores(MetricDepth, node(pair.First).f, node(pair.Second).f)
+			pairSolved := nodes[pair.First].solved && nodes[pair.Second].solved
+			// Kalo f-nya sama, pasangan yang udah solved didahuluin
+			if value < f || (value == f && pairSolved && !solved) {
+				f, best, solved = value, pair, pairSolved
+			}
+		}
+		changed := f != n.f || best != n.best || solved != n.solved
+		n.f, n.best, n.solved = f, best, solved
+		return changed
+	}
+
+	root := node(target)
+	c.record(TraceEvent{Kind: TraceEnqueue, Element: target})
+	for !root.solved && root.f < unreachableScore {
+		// Cari ujung resep terbaik sementara yang belum dibuka
+		tip, ok := "", false
+		visited := make(map[string]bool)
+		stack := []string{target}
+		for len(stack) > 0 && !ok {
+			elem := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			n := nodes[elem]
+			if visited[elem] || n.solved {
+				continue
+			}
+			visited[elem] = true
+			if !n.expanded {
+				tip, ok = elem, true
+				continue
+			}
+			stack = append(stack, n.best.Second, n.best.First)
+		}
+		if !ok {
+			break
+		}
+
+		// Buka tip
+		c.expand()
+		c.record(TraceEvent{Kind: TraceDequeue, Element: tip})
+		n := nodes[tip]
+		n.expanded = true
+		n.pairs = uniquePairs(filterValidPairs(ds.RevCombinations[tip], tip, ds.TierMap, policy))
+		if levels != nil {
+			n.pairs = filterByLevel(n.pairs, tip, levels)
+		}
+		c.examine(len(n.pairs))
+		c.recordRejected(tip, ds.RevCombinations[tip], ds, policy)
+		for _, pair := range n.pairs {
+			c.recordPair(tip, pair, tip, true, "")
+			for _, ingredient := range []string{pair.First, pair.Second} {
+				if _, exists := nodes[ingredient]; !exists {
+					node(ingredient)
+					c.record(TraceEvent{Kind: TraceEnqueue, Element: ingredient})
+				}
+			}
+			parents[pair.First] = append(parents[pair.First], tip)
+			if pair.Second != pair.First {
+				parents[pair.Second] = append(parents[pair.Second], tip)
+			}
+		}
+
+		// Rambatin perubahan ke elemen-elemen yang make tip
+		queue := []string{tip}
+		revise(tip)
+		for len(queue) > 0 {
+			c.observeFrontier(len(queue))
+			current := queue[0]
+			queue = queue[1:]
+			for _, parent := range parents[current] {
+				if revise(parent) {
+					queue = append(queue, parent)
+				}
+			}
+		}
+	}
+
+	if !root.solved {
+		return result
+	}
+	stack := []string{target}
+	for len(stack) > 0 {
+		elem := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, done := result[elem]; done || ds.IsBase(elem) {
+			continue
+		}
+		best := nodes[elem].best
+		result[elem] = Element{Source: best.First, Partner: best.Second}
+		stack = append(stack, best.First, best.Second)
+	}
+	return result
+}
+
+// tierHeuristic batas bawah kedalaman tiap elemen yang diturunin dari tier.
+// Tier di data wiki itu kedalaman minimal dari 4 elemen klasik; elemen dasar
+// tambahan (misalnya Dinosaur di Little Alchemy 2) bisa motong paling banyak
+// sebanyak tier-nya sendiri, jadi awalnya h = tier - tier elemen dasar tertinggi
+// (minimal 1 buat elemen non-dasar). Tier dari sumber lain (CSV, override, dataset
+// custom) bisa asal, jadi h terus diturunin sampai konsisten: h(produk) <=
+// 1 + max(h(bahan)) buat semua pasangan di data. Dengan h elemen dasar 0, h yang
+// konsisten gak pernah ngelebihin kedalaman sebenarnya di policy mana pun, karena
+// policy cuma bisa ngebuang pasangan. Dihitung sekali per dataset.
+func tierHeuristic(ds *Dataset) map[string]int {
+	ds.heuristicOnce.Do(func() {
+		maxBaseTier := 0
+		for _, base := range ds.BaseElements {
+			maxBaseTier = max(maxBaseTier, ds.TierMap[base])
+		}
+
+		heuristic := make(map[string]int, len(ds.RevCombinations)+len(ds.BaseElements))
+		for elem := range ds.RevCombinations {
+			heuristic[elem] = max(1, ds.TierMap[elem]-maxBaseTier)
+		}
+		for _, base := range ds.BaseElements {
+			heuristic[base] = 0
+		}
+
+		// Bahan yang gak pernah dibikin dan bukan elemen dasar gak punya h;
+		// pasangan yang make bahan itu emang gak pernah bisa dipake
+		for changed := true; changed; {
+			changed = false
+			for product, pairs := range ds.RevCombinations {
+				if ds.IsBase(product) {
+					continue
+				}
+				for _, pair := range pairs {
+					first, firstOk := heuristic[pair.First]
+					second, secondOk := heuristic[pair.Second]
+					if firstOk && secondOk && heuristic[product] > 1+max(first, second) {
+						heuristic[product] = 1 + max(first, second)
+						changed = true
+					}
+				}
+			}
+		}
+		ds.heuristic = heuristic
+	})
+	return ds.heuristic
+}
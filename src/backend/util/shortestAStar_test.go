@@ -0,0 +1,90 @@
+package util_test
+
+import (
+	"testing"
+
+	"backend/util"
+)
+
+// misleadingTierDataset tier yang bukan kedalaman minimal, kayak data CSV atau
+// override: X tier 5 padahal langsung dari elemen dasar, jadi T (tier 6) paling
+// pendek lewat X (kedalaman 2), bukan lewat D (kedalaman 3).
+func misleadingTierDataset() *util.Dataset {
+	recipes := []struct{ first, second, product string }{
+		{"A", "B", "C"},
+		{"C", "A", "D"},
+		{"D", "B", "T"},
+		{"A", "A", "X"},
+		{"X", "B", "T"},
+	}
+	tiers := map[string]int{"A": 0, "B": 0, "C": 1, "D": 2, "X": 5, "T": 6}
+
+	combinations := make(map[util.Pair]string)
+	revCombinations := make(map[string][]util.Pair)
+	for _, r := range recipes {
+		combinations[util.Pair{First: r.first, Second: r.second}] = r.product
+		combinations[util.Pair{First: r.second, Second: r.first}] = r.product
+		revCombinations[r.product] = append(revCombinations[r.product],
+			util.Pair{First: r.first, Second: r.second}, util.Pair{First: r.second, Second: r.first})
+	}
+	return util.NewDataset("misleading-tiers", []string{"A", "B"}, combinations, revCombinations, tiers)
+}
+
+// optimalSearches algoritma satu resep yang diiklanin Optimal di registry
+var optimalSearches = map[string]func(string, *util.Dataset, util.ValidityPolicy) map[string]util.Element{
+	"A*":    util.ShortestAStar,
+	"IDDFS": util.ShortestIddfs,
+}
+
+func TestOptimalSearchesMisleadingTiers(t *testing.T) {
+	ds := misleadingTierDataset()
+	for name, search := range optimalSearches {
+		for _, policy := range testPolicies {
+			for _, target := range sortedElements(ds) {
+				if ds.IsBase(target) {
+					continue
+				}
+				t.Run(name, func(t *testing.T) {
+					checkOptimal(t, ds, policy, target, search(target, ds, policy), bruteForceDepth(ds, policy, target))
+				})
+			}
+		}
+	}
+}
+
+func TestOptimalSearchesFixture(t *testing.T) {
+	ds := fixtureDataset()
+	for name, search := range optimalSearches {
+		for _, policy := range testPolicies {
+			for _, target := range sortedElements(ds) {
+				if ds.IsBase(target) {
+					continue
+				}
+				t.Run(name, func(t *testing.T) {
+					checkOptimal(t, ds, policy, target, search(target, ds, policy), bruteForceDepth(ds, policy, target))
+				})
+			}
+		}
+	}
+}
+
+func TestOptimalSearchesRealData(t *testing.T) {
+	ds := loadRealDataset(t)
+	for name, search := range optimalSearches {
+		for _, policy := range testPolicies {
+			levels := util.DerivationLevels(ds, policy)
+			for _, target := range sortedElements(ds) {
+				if ds.IsBase(target) {
+					continue
+				}
+				want, reachable := levels[target]
+				if !reachable {
+					want = -1
+				}
+				t.Run(name, func(t *testing.T) {
+					checkOptimal(t, ds, policy, target, search(target, ds, policy), want)
+				})
+			}
+		}
+	}
+}
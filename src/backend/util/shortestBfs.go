@@ -2,57 +2,75 @@ package util
 
 // ShortestBfs implementasi algoritma BFS dengan batasan tingkatan
 // buat nyari jalur terpendek bikin elemen target.
-// Cuma mempertimbangkan kombinasi dimana kedua bahan dari tier lebih rendah dari produk.
-func ShortestBfs(target string, combinations map[Pair]string, tierMap map[string]int) map[string]Element {
+// Cuma mempertimbangkan kombinasi yang diizinkan policy (default: kedua bahan
+// dari tier lebih rendah dari produk). Map kosong kalo target gak bisa dibikin.
+func ShortestBfs(target string, ds *Dataset, policy ValidityPolicy) map[string]Element {
+	recipe, _ := shortestBfs(target, ds, policy, nil)
+	return recipe
+}
+
+// ShortestBfsWithStats ShortestBfs beserta statistik pencariannya
+func ShortestBfsWithStats(target string, ds *Dataset, policy ValidityPolicy) (map[string]Element, SearchStats) {
+	c := newSearchCounters()
+	recipe, _ := shortestBfs(target, ds, policy, c)
+	return recipe, c.finish()
+}
+
+// shortestBfs ngembaliin found false (dan map kosong) kalo target gak kejangkau
+func shortestBfs(target string, ds *Dataset, policy ValidityPolicy, c *searchCounters) (map[string]Element, bool) {
 	// Siapin queue dengan elemen dasar
-	queue := make([]string, len(BaseElements))
-	copy(queue, BaseElements)
+	queue := make([]string, len(ds.BaseElements))
+	copy(queue, ds.BaseElements)
 
 	// Tandain elemen yang udah dilihat
-	seen := make(map[string]bool, len(BaseElements))
-	for _, b := range BaseElements {
+	seen := make(map[string]bool, len(ds.BaseElements))
+	for _, b := range ds.BaseElements {
 		seen[b] = true
+		c.record(TraceEvent{Kind: TraceEnqueue, Element: b})
 	}
 
 	// Simpan resep untuk setiap elemen yang dihasilkan
 	prev := make(map[string]Element)
 
 	// Loop BFS
+	found := false
 	for i := 0; i < len(queue); i++ {
 		current := queue[i]
+		c.observeFrontier(len(queue) - i)
+		c.record(TraceEvent{Kind: TraceDequeue, Element: current})
 
 		// Kalo udah ketemu target, berhenti pencarian
 		if current == target {
+			found = true
 			break
 		}
-
-		// Ambil tier elemen saat ini
-		currentTier := tierMap[current]
+		c.expand()
+		c.examine(len(seen))
 
 		// Coba kombinasiin elemen saat ini dengan semua elemen yang udah dilihat
 		for partner := range seen {
-			// Ambil tier partner
-			partnerTier := tierMap[partner]
-			
 			// Coba bikin produk dari pasangan ini
 			pair := Pair{First: current, Second: partner}
-			if product, exists := combinations[pair]; exists {
-				// Ambil tier produk
-				productTier := tierMap[product]
-				
-				// Cek apakah ini kombinasi tier yang valid:
-				// Produk harus tier lebih tinggi dari kedua bahan
-				if currentTier < productTier && partnerTier < productTier {
+			if product, exists := ds.Combinations[pair]; exists {
+				// Cek apakah kombinasi ini diizinkan policy
+				allowed := policy.Allows(product, pair, ds.TierMap)
+				c.recordPair(current, pair, product, allowed, "")
+				if allowed {
 					// Kalo produk baru (belum pernah dilihat), tambahin ke queue
 					if !seen[product] {
 						seen[product] = true
 						prev[product] = Element{Source: current, Partner: partner}
 						queue = append(queue, product)
+						c.record(TraceEvent{Kind: TraceEnqueue, Element: product})
 					}
 				}
 			}
 		}
 	}
 
-	return prev
-}
\ No newline at end of file
+	// prev isinya setengah jadi kalo target gak pernah ketemu
+	if !found {
+		return make(map[string]Element), false
+	}
+	return prev, true
+}
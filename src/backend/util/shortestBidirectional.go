@@ -1,207 +1,315 @@
 package util
 
+import "sort"
+
 // ShortestBidirectional implementasi algoritma pencarian bidirectional (dua arah)
-// yang mencari jalur terpendek untuk membuat elemen target dengan
-// menjalankan BFS dari elemen dasar (maju) dan dari target (mundur) secara bersamaan
-func ShortestBidirectional(target string, combinations map[Pair]string, revCombinations map[string][]Pair, tierMap map[string]int) map[string]Element {
-	// Siapin queue untuk arah maju (dari elemen dasar)
-	forwardQueue := make([]string, len(BaseElements))
-	copy(forwardQueue, BaseElements)
-
-	// Siapin queue untuk arah mundur (dari target)
-	backwardQueue := []string{target}
-
-	// Tandain elemen yang udah dilihat di arah maju
-	forwardSeen := make(map[string]bool, len(BaseElements))
-	for _, b := range BaseElements {
-		forwardSeen[b] = true
+// yang mencari resep dengan kedalaman minimal buat elemen target. Arah maju
+// ngitung level derivasi per lapis dari elemen dasar, arah mundur ngebuka graf
+// AND/OR dari target (satu elemen bisa dibikin dari beberapa pasangan, tiap
+// pasangan butuh dua-duanya). Pencarian berhenti begitu batas atas dan batas
+// bawah kedalaman target sama, jadi hasilnya pasti kedalaman minimal, sama kayak
+// DerivationLevels.
+func ShortestBidirectional(target string, ds *Dataset, policy ValidityPolicy) map[string]Element {
+	return shortestBidirectional(target, ds, policy, nil)
+}
+
+// ShortestBidirectionalWithStats ShortestBidirectional beserta statistik pencariannya
+func ShortestBidirectionalWithStats(target string, ds *Dataset, policy ValidityPolicy) (map[string]Element, SearchStats) {
+	c := newSearchCounters()
+	recipe := shortestBidirectional(target, ds, policy, c)
+	return recipe, c.finish()
+}
+
+// bidirUnknown nilai buat elemen yang belum ketahuan bisa dibikin
+const bidirUnknown = unreachableScore
+
+// bidirSearch state pencarian dua arah.
+//
+// Arah maju: levels berisi level derivasi pasti buat semua elemen dengan level
+// <= forwardDepth; elemen yang gak ada di levels pasti levelnya > forwardDepth.
+// forwardVia nyimpen pasangan yang ngasilin level itu (bahannya levelnya lebih kecil).
+//
+// Arah mundur: backwardPairs berisi pasangan valid buat tiap elemen yang udah
+// dibuka dari target. Elemen yang ketemu tapi belum dibuka ada di backwardFrontier.
+type bidirSearch struct {
+	ds     *Dataset
+	policy ValidityPolicy
+	target string
+	c      *searchCounters
+
+	levels          map[string]int
+	forwardVia      map[string]Element
+	forwardFrontier []string // Elemen dengan level == forwardDepth
+	forwardDepth    int
+
+	backwardSeen     map[string]bool
+	backwardPairs    map[string][]Pair
+	backwardOrder    []string // Elemen yang udah dibuka, urut dibuka
+	backwardFrontier []string
+}
+
+func shortestBidirectional(target string, ds *Dataset, policy ValidityPolicy, c *searchCounters) map[string]Element {
+	result := make(map[string]Element)
+	if _, exists := ds.RevCombinations[target]; !exists || ds.IsBase(target) {
+		return result
 	}
 
-	// Tandain elemen yang udah dilihat di arah mundur
-	backwardSeen := make(map[string]bool)
-	backwardSeen[target] = true
-
-	// Simpan resep untuk setiap elemen yang dihasilkan dari arah maju
-	forwardRecipes := make(map[string]Element)
-
-	// Simpan elemen apa aja yang bisa bikin suatu elemen dari arah mundur
-	// Key: elemen yang ditemukan dari arah mundur
-	// Value: pasangan bahan yang bisa membuat elemen tersebut
-	backwardRecipes := make(map[string][]Pair)
-
-	// Elemen yang jadi titik temu kedua arah pencarian
-	var meetingPoint string
-	
-	// Flag untuk tracking apakah sudah ketemu
-	found := false
-
-	// Loop sampai ketemu titik temu atau salah satu queue kosong
-	for len(forwardQueue) > 0 && len(backwardQueue) > 0 && !found {
-		// ===== FORWARD SEARCH (dari elemen dasar ke target) =====
-		// Jalanin satu langkah BFS dari arah maju
-		if len(forwardQueue) > 0 {
-			// Ambil elemen dari queue
-			current := forwardQueue[0]
-			forwardQueue = forwardQueue[1:]
-
-			// Cek apakah elemen ini juga ada di backwardSeen (berarti ketemu titik temu)
-			if backwardSeen[current] {
-				meetingPoint = current
-				found = true
-				break
-			}
+	s := &bidirSearch{
+		ds:            ds,
+		policy:        policy,
+		target:        target,
+		c:             c,
+		levels:        make(map[string]int, len(ds.TierMap)),
+		forwardVia:    make(map[string]Element),
+		backwardSeen:  map[string]bool{target: true},
+		backwardPairs: make(map[string][]Pair),
+	}
 
-			// Ambil tier elemen saat ini
-			currentTier := tierMap[current]
-
-			// Coba kombinasiin dengan elemen yang udah diketahui
-			for partner := range forwardSeen {
-				// Ambil tier partner
-				partnerTier := tierMap[partner]
-				
-				// Coba bikin produk dari pasangan ini
-				pair := Pair{First: current, Second: partner}
-				if product, exists := combinations[pair]; exists {
-					// Ambil tier produk
-					productTier := tierMap[product]
-					
-					// Cek tier constraint: produk harus dari tier lebih tinggi
-					if currentTier < productTier && partnerTier < productTier {
-						// Kalo produk baru, tambahin ke queue maju
-						if !forwardSeen[product] {
-							forwardSeen[product] = true
-							forwardRecipes[product] = Element{Source: current, Partner: partner}
-							forwardQueue = append(forwardQueue, product)
-						}
-					}
-				}
+	// Lapis 0 arah maju: elemen dasar
+	base := append([]string(nil), ds.BaseElements...)
+	sort.Strings(base)
+	for _, elem := range base {
+		if _, exists := s.levels[elem]; exists {
+			continue
+		}
+		s.levels[elem] = 0
+		s.forwardFrontier = append(s.forwardFrontier, elem)
+		c.record(TraceEvent{Kind: TraceEnqueue, Element: elem, Direction: TraceForward})
+	}
+	s.backwardFrontier = []string{target}
+	c.record(TraceEvent{Kind: TraceEnqueue, Element: target, Direction: TraceBackward})
+
+	for {
+		if _, reached := s.levels[target]; reached {
+			break
+		}
+
+		upper, choice := s.upperBounds()
+		best, found := upper[target]
+		// Frontier mundur habis: semua bahan yang mungkin dipake udah dibuka,
+		// jadi batas atasnya udah pasti kedalaman minimal
+		if len(s.backwardFrontier) == 0 {
+			if !found {
+				return result
 			}
+			return s.stitch(choice)
+		}
+		if found && best <= s.lowerBound() {
+			return s.stitch(choice)
+		}
+		// Frontier maju habis: semua elemen yang bisa dibikin udah punya level
+		if len(s.forwardFrontier) == 0 {
+			return result
 		}
 
-		// ===== BACKWARD SEARCH (dari target ke elemen dasar) =====
-		// Jalanin satu langkah BFS dari arah mundur
-		if len(backwardQueue) > 0 {
-			// Ambil elemen dari queue
-			current := backwardQueue[0]
-			backwardQueue = backwardQueue[1:]
+		c.observeFrontier(len(s.forwardFrontier) + len(s.backwardFrontier))
+		// Buka sisi yang frontier-nya lebih kecil, satu lapis penuh
+		if len(s.forwardFrontier) <= len(s.backwardFrontier) {
+			s.expandForward()
+		} else {
+			s.expandBackward()
+		}
+	}
+
+	return s.forwardRecipe(target, result)
+}
 
-			// Cek apakah elemen ini juga ada di forwardSeen (berarti ketemu titik temu)
-			if forwardSeen[current] {
-				meetingPoint = current
-				found = true
-				break
+// expandForward ngitung satu lapis level berikutnya. Produk yang belum punya level
+// dan bahannya sama-sama udah dikenal (salah satunya di lapis terakhir) dapet
+// level forwardDepth + 1. Kombinasi diambil dari reactionIndex (isinya sama kayak
+// RevCombinations), jadi pair yang ngasilin lebih dari satu produk ikut kehitung.
+func (s *bidirSearch) expandForward() {
+	ds, c := s.ds, s.c
+	index := ds.reactionIndex()
+	depth := s.forwardDepth + 1
+	var next []string
+	for _, current := range s.forwardFrontier {
+		c.record(TraceEvent{Kind: TraceDequeue, Element: current, Direction: TraceForward})
+		c.expand()
+		c.examine(len(index.consumers[current]))
+		for _, id := range index.consumers[current] {
+			r := index.all[id]
+			if !r.IsPair() {
+				continue
 			}
+			pair := Pair{First: r.Ingredients[0], Second: r.Ingredients[1]}
+			first, firstOk := s.levels[pair.First]
+			second, secondOk := s.levels[pair.Second]
+			// Bahan yang baru ketemu di lapis ini belum boleh dipake
+			if !firstOk || !secondOk || first >= depth || second >= depth {
+				continue
+			}
+			product := r.Products[0]
+			allowed := s.policy.Allows(product, pair, ds.TierMap)
+			c.recordPair(current, pair, product, allowed, TraceForward)
+			if !allowed {
+				continue
+			}
+			if _, seen := s.levels[product]; !seen {
+				s.levels[product] = depth
+				s.forwardVia[product] = Element{Source: pair.First, Partner: pair.Second}
+				next = append(next, product)
+				c.record(TraceEvent{Kind: TraceEnqueue, Element: product, Direction: TraceForward})
+			}
+		}
+	}
+
+	sort.Strings(next)
+	s.forwardFrontier = next
+	s.forwardDepth = depth
+}
 
-			// Ambil tier elemen saat ini
-			currentTier := tierMap[current]
-
-			// Cek semua pasangan yang bisa menghasilkan elemen ini
-			for _, pair := range revCombinations[current] {
-				// Ambil tier kedua bahan
-				firstTier := tierMap[pair.First]
-				secondTier := tierMap[pair.Second]
-				
-				// Cek tier constraint: bahan harus dari tier lebih rendah
-				if firstTier < currentTier && secondTier < currentTier {
-					// Catat pasangan ini sebagai pembuat elemen current
-					backwardRecipes[current] = append(backwardRecipes[current], pair)
-					
-					// Tambahin kedua bahan ke queue mundur kalo belum pernah dilihat
-					if !backwardSeen[pair.First] && !isBaseElement(pair.First) {
-						backwardSeen[pair.First] = true
-						backwardQueue = append(backwardQueue, pair.First)
-					}
-					
-					if !backwardSeen[pair.Second] && !isBaseElement(pair.Second) {
-						backwardSeen[pair.Second] = true
-						backwardQueue = append(backwardQueue, pair.Second)
-					}
+// expandBackward ngebuka satu lapis arah mundur: semua pasangan valid buat tiap
+// elemen di frontier dicatat, bahannya jadi frontier berikutnya. Elemen yang udah
+// punya level maju gak perlu dibuka lagi, di situ kedua arah ketemu.
+func (s *bidirSearch) expandBackward() {
+	ds, c := s.ds, s.c
+	var next []string
+	for _, current := range s.backwardFrontier {
+		c.record(TraceEvent{Kind: TraceDequeue, Element: current, Direction: TraceBackward})
+		if _, reached := s.levels[current]; reached {
+			continue
+		}
+
+		c.expand()
+		pairs := ds.RevCombinations[current]
+		c.examine(len(pairs))
+		var valid []Pair
+		for _, pair := range pairs {
+			allowed := s.policy.Allows(current, pair, ds.TierMap)
+			c.recordPair(current, pair, current, allowed, TraceBackward)
+			if !allowed {
+				continue
+			}
+			valid = append(valid, pair)
+			for _, ingredient := range []string{pair.First, pair.Second} {
+				if !s.backwardSeen[ingredient] && !ds.IsBase(ingredient) {
+					s.backwardSeen[ingredient] = true
+					next = append(next, ingredient)
+					c.record(TraceEvent{Kind: TraceEnqueue, Element: ingredient, Direction: TraceBackward})
 				}
 			}
 		}
+		s.backwardPairs[current] = valid
+		s.backwardOrder = append(s.backwardOrder, current)
 	}
+	s.backwardFrontier = next
+}
 
-	// Kalo gak ketemu titik temu, return kosong
-	if !found {
-		return make(map[string]Element)
+// upperBounds kedalaman resep terbaik yang udah pasti bisa dibikin buat tiap
+// elemen yang udah dibuka mundur, pake level maju buat elemen yang udah ketemu.
+// Relaksasi dari atas kayak minimalScores, jadi tiap pasangan yang kepilih di
+// choice bahannya punya nilai lebih kecil dari produknya (gak mungkin siklik).
+func (s *bidirSearch) upperBounds() (map[string]int, map[string]Pair) {
+	upper := make(map[string]int, len(s.backwardOrder))
+	choice := make(map[string]Pair, len(s.backwardOrder))
+	get := func(elem string) int {
+		if level, exists := s.levels[elem]; exists {
+			return level
+		}
+		if value, exists := upper[elem]; exists {
+			return value
+		}
+		return bidirUnknown
 	}
 
-	// Bentuk resep lengkap dari titik temu
-	// Kalo titik temu adalah target, kita udah selesai
-	if meetingPoint == target {
-		return forwardRecipes
+	for changed := true; changed; {
+		changed = false
+		for _, elem := range s.backwardOrder {
+			if _, exists := s.levels[elem]; exists {
+				continue
+			}
+			best := get(elem)
+			for _, pair := range s.backwardPairs[elem] {
+				if value := combineScores(MetricDepth, get(pair.First), get(pair.Second)); value < best {
+					best = value
+					choice[elem] = pair
+				}
+			}
+			if best < get(elem) {
+				upper[elem] = best
+				changed = true
+			}
+		}
 	}
+	return upper, choice
+}
 
-	// Kalo titik temu bukan target, kita perlu gabungin resep maju dan mundur
-	result := make(map[string]Element)
-	
-	// Salin semua resep dari arah maju ke hasil
-	for elem, recipe := range forwardRecipes {
-		result[elem] = recipe
+// lowerBound batas bawah kedalaman target. Elemen tanpa level maju pasti
+// kedalamannya > forwardDepth; elemen yang udah dibuka mundur gak mungkin
+// lebih dangkal dari pasangan terbaiknya. Nilainya cuma naik tiap putaran dan
+// selalu <= kedalaman sebenarnya, jadi boleh berhenti kapan aja.
+func (s *bidirSearch) lowerBound() int {
+	floor := s.forwardDepth + 1
+	lower := make(map[string]int, len(s.backwardOrder))
+	get := func(elem string) int {
+		if level, exists := s.levels[elem]; exists {
+			return level
+		}
+		if value, exists := lower[elem]; exists {
+			return value
+		}
+		return floor
 	}
-	
-	// Mulai dari titik temu, buat resep untuk semua elemen di jalur mundur
-	completePath := completeBackwardPath(meetingPoint, target, backwardRecipes, forwardRecipes, revCombinations, tierMap)
-	for elem, recipe := range completePath {
-		result[elem] = recipe
+
+	for round := 0; round <= len(s.backwardOrder); round++ {
+		changed := false
+		for _, elem := range s.backwardOrder {
+			if _, exists := s.levels[elem]; exists {
+				continue
+			}
+			best := bidirUnknown
+			for _, pair := range s.backwardPairs[elem] {
+				best = min(best, combineScores(MetricDepth, get(pair.First), get(pair.Second)))
+			}
+			best = max(best, floor)
+			if best > get(elem) {
+				lower[elem] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
 	}
-	
-	return result
+	return get(s.target)
 }
 
-// completeBackwardPath menyelesaikan jalur mundur dari titik temu ke target
-// dengan memastikan kita punya resep valid untuk semua elemen di jalur
-func completeBackwardPath(meetingPoint, target string, backwardRecipes map[string][]Pair, 
-						 forwardRecipes map[string]Element, revCombinations map[string][]Pair, 
-						 tierMap map[string]int) map[string]Element {
+// stitch nyusun resep dari target: elemen yang udah punya level maju pake resep
+// maju (titik temu), sisanya pake pasangan terbaik dari arah mundur
+func (s *bidirSearch) stitch(choice map[string]Pair) map[string]Element {
 	result := make(map[string]Element)
-	
-	// Buat rekonstruksi resep dari titik temu ke target
-	toProcess := []string{target}
-	processed := make(map[string]bool)
-	
-	// Tandai titik temu sebagai sudah diproses (kita udah punya resepnya)
-	processed[meetingPoint] = true
-	
-	for len(toProcess) > 0 {
-		current := toProcess[0]
-		toProcess = toProcess[1:]
-		
-		if processed[current] {
+	stack := []string{s.target}
+	for len(stack) > 0 {
+		elem := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, done := result[elem]; done || s.ds.IsBase(elem) {
 			continue
 		}
-		
-		// Kita perlu cari resep untuk current
-		if pairs, exists := backwardRecipes[current]; exists && len(pairs) > 0 {
-			// Ambil pasangan bahan pertama sebagai resep
-			pair := pairs[0]
-			result[current] = Element{Source: pair.First, Partner: pair.Second}
-			
-			// Tambahkan kedua bahan ke antrian untuk diproses
-			// kecuali jika sudah ada di forwardRecipes atau sudah diproses
-			if !processed[pair.First] && forwardRecipes[pair.First].Source == "" {
-				toProcess = append(toProcess, pair.First)
-			}
-			
-			if !processed[pair.Second] && forwardRecipes[pair.Second].Source == "" {
-				toProcess = append(toProcess, pair.Second)
-			}
-		} else {
-			// Kalo gak ada di backwardRecipes, coba cari resep dengan ShortestDfs
-			// Ini bisa terjadi karena kita melompati beberapa elemen dalam pencarian mundur
-			miniResult := ShortestDfs(current, revCombinations, tierMap)
-			
-			// Gabungkan dengan hasil kita
-			for elem, recipe := range miniResult {
-				if !processed[elem] {
-					result[elem] = recipe
-					processed[elem] = true
-				}
-			}
+		if _, reached := s.levels[elem]; reached {
+			s.c.record(TraceEvent{Kind: TraceMeet, Element: elem})
+			s.forwardRecipe(elem, result)
+			continue
+		}
+		pair := choice[elem]
+		result[elem] = Element{Source: pair.First, Partner: pair.Second}
+		stack = append(stack, pair.First, pair.Second)
+	}
+	return result
+}
+
+// forwardRecipe nambahin resep maju element (beserta bahan-bahannya) ke result
+func (s *bidirSearch) forwardRecipe(element string, result map[string]Element) map[string]Element {
+	stack := []string{element}
+	for len(stack) > 0 {
+		elem := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, done := result[elem]; done || s.ds.IsBase(elem) {
+			continue
 		}
-		
-		processed[current] = true
+		sources := s.forwardVia[elem]
+		result[elem] = sources
+		stack = append(stack, sources.Source, sources.Partner)
 	}
-	
 	return result
 }
@@ -0,0 +1,216 @@
+package util_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"backend/scraper"
+	"backend/util"
+)
+
+var testPolicies = []util.ValidityPolicy{util.StrictTierPolicy{}, util.NonStrictTierPolicy{}, util.AcyclicPolicy{}}
+
+// fixtureDataset dataset kecil yang beda hasilnya di tiap policy:
+//   - A+B ngasilin C dan G (multi-produk); tier G (3) gak sesuai kedalaman aslinya (1)
+//   - E: strict-tier cuma bisa lewat X (kedalaman 3), policy lain bisa lewat G (2)
+//   - H+A -> E bikin siklus E -> H -> E di policy acyclic
+//   - U butuh dirinya sendiri, jadi mustahil dibikin
+func fixtureDataset() *util.Dataset {
+	type recipe struct{ first, second, product string }
+	recipes := []recipe{
+		{"A", "B", "C"},
+		{"A", "B", "G"},
+		{"A", "A", "D"},
+		{"C", "D", "X"},
+		{"X", "A", "E"},
+		{"G", "B", "E"},
+		{"E", "A", "H"},
+		{"H", "A", "E"},
+		{"U", "A", "U"},
+		{"H", "C", "Z"},
+	}
+	tiers := map[string]int{"A": 0, "B": 0, "C": 1, "D": 1, "X": 2, "G": 3, "E": 3, "H": 4, "U": 5, "Z": 5}
+
+	combinations := make(map[util.Pair]string)
+	revCombinations := make(map[string][]util.Pair)
+	for _, r := range recipes {
+		combinations[util.Pair{First: r.first, Second: r.second}] = r.product
+		combinations[util.Pair{First: r.second, Second: r.first}] = r.product
+		revCombinations[r.product] = append(revCombinations[r.product],
+			util.Pair{First: r.first, Second: r.second}, util.Pair{First: r.second, Second: r.first})
+	}
+	return util.NewDataset("fixture", []string{"A", "B"}, combinations, revCombinations, tiers)
+}
+
+// loadRealDataset dataset Little Alchemy 2 dari data/recipes.json di root repo
+func loadRealDataset(t *testing.T) *util.Dataset {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping real data in -short mode")
+	}
+	path := filepath.Join("..", "..", "..", "data", "recipes.json")
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("real data not available: %v", err)
+	}
+	recipes, err := scraper.LoadRecipeJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scraper.BuildDataset("la2", util.LittleAlchemy2BaseElements, recipes)
+}
+
+// sortedElements semua elemen dataset, urut biar pesan error-nya stabil
+func sortedElements(ds *util.Dataset) []string {
+	elements := make([]string, 0, len(ds.TierMap))
+	for elem := range ds.TierMap {
+		elements = append(elements, elem)
+	}
+	sort.Strings(elements)
+	return elements
+}
+
+// checkRecipe ngecek resep lengkap dan valid: tiap elemen non-dasar yang dipake
+// punya pasangan yang beneran ngasilin elemen itu, diizinin policy, dan gak ada
+// elemen yang butuh dirinya sendiri. Ngembaliin kedalaman resepnya.
+func checkRecipe(t *testing.T, ds *util.Dataset, policy util.ValidityPolicy, target string, recipe map[string]util.Element) int {
+	t.Helper()
+	onPath := make(map[string]bool)
+	depths := make(map[string]int)
+
+	var visit func(elem string) int
+	visit = func(elem string) int {
+		if ds.IsBase(elem) {
+			return 0
+		}
+		if depth, done := depths[elem]; done {
+			return depth
+		}
+		if onPath[elem] {
+			t.Errorf("%s (%s): %s is needed to make itself", target, policy.Name(), elem)
+			return 0
+		}
+		sources, exists := recipe[elem]
+		if !exists || sources.Source == "" {
+			t.Errorf("%s (%s): recipe has no step for %s", target, policy.Name(), elem)
+			return 0
+		}
+		pair := util.Pair{First: sources.Source, Second: sources.Partner}
+		if !producesWith(ds, elem, pair) {
+			t.Errorf("%s (%s): %s + %s does not make %s", target, policy.Name(), pair.First, pair.Second, elem)
+		}
+		if !policy.Allows(elem, pair, ds.TierMap) {
+			t.Errorf("%s (%s): %s + %s -> %s is not allowed", target, policy.Name(), pair.First, pair.Second, elem)
+		}
+
+		onPath[elem] = true
+		depth := 1 + max(visit(pair.First), visit(pair.Second))
+		onPath[elem] = false
+		depths[elem] = depth
+		return depth
+	}
+	return visit(target)
+}
+
+func producesWith(ds *util.Dataset, product string, pair util.Pair) bool {
+	for _, candidate := range ds.RevCombinations[product] {
+		if candidate == pair || (candidate.First == pair.Second && candidate.Second == pair.First) {
+			return true
+		}
+	}
+	return false
+}
+
+// bruteForceDepth kedalaman minimal target dengan nyoba semua pohon resep
+// (elemen gak boleh jadi leluhur dirinya sendiri). -1 kalo mustahil.
+func bruteForceDepth(ds *util.Dataset, policy util.ValidityPolicy, target string) int {
+	onPath := make(map[string]bool)
+	var depth func(elem string) int
+	depth = func(elem string) int {
+		if ds.IsBase(elem) {
+			return 0
+		}
+		if onPath[elem] {
+			return -1
+		}
+		onPath[elem] = true
+		defer delete(onPath, elem)
+
+		best := -1
+		for _, pair := range ds.RevCombinations[elem] {
+			if !policy.Allows(elem, pair, ds.TierMap) {
+				continue
+			}
+			first, second := depth(pair.First), depth(pair.Second)
+			if first < 0 || second < 0 {
+				continue
+			}
+			if d := 1 + max(first, second); best < 0 || d < best {
+				best = d
+			}
+		}
+		return best
+	}
+	return depth(target)
+}
+
+// checkOptimal nyocokin hasil search satu target sama kedalaman minimal want (-1 = mustahil)
+func checkOptimal(t *testing.T, ds *util.Dataset, policy util.ValidityPolicy, target string, recipe map[string]util.Element, want int) {
+	t.Helper()
+	if want < 0 {
+		if len(recipe) > 0 {
+			t.Errorf("%s (%s): unreachable target got a recipe %v", target, policy.Name(), recipe)
+		}
+		return
+	}
+	if len(recipe) == 0 {
+		t.Errorf("%s (%s): no recipe, want depth %d", target, policy.Name(), want)
+		return
+	}
+	if got := checkRecipe(t, ds, policy, target, recipe); got != want {
+		t.Errorf("%s (%s): recipe depth %d, want %d", target, policy.Name(), got, want)
+	}
+}
+
+func TestShortestBidirectionalFixture(t *testing.T) {
+	ds := fixtureDataset()
+	for _, policy := range testPolicies {
+		levels := util.DerivationLevels(ds, policy)
+		for _, target := range sortedElements(ds) {
+			if ds.IsBase(target) {
+				continue
+			}
+			want := bruteForceDepth(ds, policy, target)
+			if level, reachable := levels[target]; (want >= 0) != reachable || (reachable && level != want) {
+				t.Errorf("%s (%s): DerivationLevels %d (reachable %v), brute force %d", target, policy.Name(), level, reachable, want)
+			}
+			checkOptimal(t, ds, policy, target, util.ShortestBidirectional(target, ds, policy), want)
+		}
+	}
+
+	// Pastiin fixture-nya beneran ngebedain policy
+	if got := bruteForceDepth(ds, util.StrictTierPolicy{}, "E"); got != 3 {
+		t.Fatalf("fixture: strict-tier depth of E = %d, want 3", got)
+	}
+	if got := bruteForceDepth(ds, util.AcyclicPolicy{}, "E"); got != 2 {
+		t.Fatalf("fixture: acyclic depth of E = %d, want 2", got)
+	}
+}
+
+func TestShortestBidirectionalRealData(t *testing.T) {
+	ds := loadRealDataset(t)
+	for _, policy := range testPolicies {
+		levels := util.DerivationLevels(ds, policy)
+		for _, target := range sortedElements(ds) {
+			if ds.IsBase(target) {
+				continue
+			}
+			want, reachable := levels[target]
+			if !reachable {
+				want = -1
+			}
+			checkOptimal(t, ds, policy, target, util.ShortestBidirectional(target, ds, policy), want)
+		}
+	}
+}
@@ -6,122 +6,190 @@ type NodeState struct {
 	Pairs            []Pair
 	ValidPairs       []Pair
 	Visited          bool
+	Failed           bool // Udah pasti gak bisa dibikin (gagalnya gak gara-gara siklus)
 }
 
-func ShortestDfs(target string, revCombinations map[string][]Pair, tierMap map[string]int) map[string]Element {
-  // Inisialisasi map hasil: elemen -> resepnya
-  result := make(map[string]Element)
-  
-  // Cek apakah target ada di kombinasi
-  if _, exists := revCombinations[target]; !exists {
-    return result
-  }
-  
-  // Map buat ngetracking status eksplorasi tiap elemen
-  nodeStates := make(map[string]*NodeState)
-  
-  // Tambahin elemen dasar ke node states dengan visited=true
-  for _, elem := range BaseElements {
-    nodeStates[elem] = &NodeState{Visited: true}
-    result[elem] = Element{} // Tandain elemen dasar dengan resep kosong
-  }
-  
-  // Tracking elemen yang lagi kita coba resolve
-  inProgress := make(map[string]bool)
-  
-  // Pake fungsi rekursif sebagai helper buat DFS
-  var explore func(element string) bool
-  explore = func(element string) bool {
-    // Elemen dasar udah selesai
-    if isBaseElement(element) {
-      return true
-    }
-    
-    // Skip kalo kita udah nemu solusi buat elemen ini
-    if state := nodeStates[element]; state != nil && state.Visited {
-      return true
-    }
-    
-    // Deteksi siklus - kalo kita udah coba resolve elemen ini di jalur saat ini
-    if inProgress[element] {
-      return false
-    }
-    
-    // Tandain sebagai sedang diproses
-    inProgress[element] = true
-    defer func() { inProgress[element] = false }()
-    
-    // Ambil atau bikin state node
-    state := nodeStates[element]
-    if state == nil {
-      pairs := revCombinations[element]
-      validPairs := filterValidPairs(pairs, element, tierMap)
-      state = &NodeState{
-        CurrentPairIndex: 0,
-        Pairs:            pairs,
-        ValidPairs:       validPairs,
-        Visited:          false,
-      }
-      nodeStates[element] = state
-    }
-    
-    // Coba tiap resep yang valid
-    for i := 0; i < len(state.ValidPairs); i++ {
-      pair := state.ValidPairs[i]
-      
-      // Catat resep ini sementara
-      result[element] = Element{
-        Source:  pair.First,
-        Partner: pair.Second,
-      }
-      
-      // Coba resolve kedua bahan
-      firstResolved := isBaseElement(pair.First) || explore(pair.First)
-      if !firstResolved {
-        continue // Coba resep berikutnya kalo bahan pertama gak bisa diresolved
-      }
-      
-      secondResolved := isBaseElement(pair.Second) || explore(pair.Second)
-      if !secondResolved {
-        continue // Coba resep berikutnya kalo bahan kedua gak bisa diresolved
-      }
-      
-      // Kedua bahan resolved - kita nemu resep valid
-      state.Visited = true
-      state.CurrentPairIndex = i + 1 // Inget resep mana yang kita pake
-      return true
-    }
-    
-    // Kalo sampe sini, gak ada resep valid yang ketemu
-    delete(result, element) // Hapus resep sementara
-    return false
-  }
-  
-  // Mulai eksplorasi dari target
-  explore(target)
-  
-  // Bersihin map hasil - hapus entri dengan resep kosong yang bukan elemen dasar
-  for key, elem := range result {
-    if !isBaseElement(key) && (elem.Source == "" || elem.Partner == "") {
-      delete(result, key)
-    }
-  }
-  
-  return result
+func ShortestDfs(target string, ds *Dataset, policy ValidityPolicy) map[string]Element {
+	recipe, _ := shortestDfs(target, ds, policy, nil)
+	return recipe
 }
 
-// Improved filterValidPairs to prioritize lower tier ingredients
-func filterValidPairs(pairs []Pair, element string, tierMap map[string]int) []Pair {
-    currentTier := tierMap[element]
-    var validPairs []Pair
-    
-    for _, pair := range pairs {
-        // Only consider pairs where both ingredients are from lower tier
-        // This prevents using equal or higher tier elements to create lower tier ones
-        if tierMap[pair.First] < currentTier && tierMap[pair.Second] < currentTier {
-            validPairs = append(validPairs, pair)
-        }
-    }
-    
-    return validPairs
-}
\ No newline at end of file
+// ShortestDfsWithStats ShortestDfs beserta statistik pencariannya
+func ShortestDfsWithStats(target string, ds *Dataset, policy ValidityPolicy) (map[string]Element, SearchStats) {
+	c := newSearchCounters()
+	recipe, _ := shortestDfs(target, ds, policy, c)
+	return recipe, c.finish()
+}
+
+// shortestDfs ngembaliin found false (dan map kosong) kalo target gak bisa dibikin
+func shortestDfs(target string, ds *Dataset, policy ValidityPolicy, c *searchCounters) (map[string]Element, bool) {
+	// Inisialisasi map hasil: elemen -> resepnya
+	result := make(map[string]Element)
+
+	// Cek apakah target ada di kombinasi
+	if _, exists := ds.RevCombinations[target]; !exists {
+		return result, false
+	}
+
+	// Map buat ngetracking status eksplorasi tiap elemen
+	nodeStates := make(map[string]*NodeState)
+
+	// Tambahin elemen dasar ke node states dengan visited=true
+	for _, elem := range ds.BaseElements {
+		nodeStates[elem] = &NodeState{Visited: true}
+		result[elem] = Element{} // Tandain elemen dasar dengan resep kosong
+	}
+
+	// Tracking elemen yang lagi kita coba resolve
+	inProgress := make(map[string]bool)
+
+	// Berapa kali pencarian nabrak siklus. Kegagalan yang gak nabrak siklus
+	// gak tergantung jalur, jadi aman diinget biar gak dicoba ulang.
+	cycleHits := 0
+
+	// Policy yang bisa siklik diarahin pake level derivasi: cuma pasangan yang
+	// bahannya levelnya lebih kecil yang dicoba, jadi DFS gak pernah muter
+	var levels map[string]int
+	if !isAcyclicByConstruction(policy) {
+		levels = DerivationLevels(ds, policy)
+	}
+
+	// Pake fungsi rekursif sebagai helper buat DFS
+	var explore func(element string) bool
+	explore = func(element string) bool {
+		// Elemen dasar udah selesai
+		if ds.IsBase(element) {
+			return true
+		}
+
+		// Skip kalo kita udah nemu solusi buat elemen ini
+		if state := nodeStates[element]; state != nil && state.Visited {
+			return true
+		}
+
+		// Skip kalo elemen ini udah pasti gagal
+		if state := nodeStates[element]; state != nil && state.Failed {
+			return false
+		}
+
+		// Deteksi siklus - kalo kita udah coba resolve elemen ini di jalur saat ini
+		if inProgress[element] {
+			cycleHits++
+			return false
+		}
+		hitsBefore := cycleHits
+
+		// Tandain sebagai sedang diproses
+		inProgress[element] = true
+		c.observeFrontier(len(inProgress))
+		c.record(TraceEvent{Kind: TraceEnqueue, Element: element})
+		defer c.record(TraceEvent{Kind: TraceDequeue, Element: element})
+		defer delete(inProgress, element)
+
+		// Ambil atau bikin state node
+		state := nodeStates[element]
+		if state == nil {
+			pairs := ds.RevCombinations[element]
+			validPairs := filterValidPairs(pairs, element, ds.TierMap, policy)
+			if levels != nil {
+				validPairs = filterByLevel(validPairs, element, levels)
+			}
+			state = &NodeState{
+				CurrentPairIndex: 0,
+				Pairs:            pairs,
+				ValidPairs:       validPairs,
+				Visited:          false,
+			}
+			nodeStates[element] = state
+			c.expand()
+
+			if c.tracing() {
+				for _, pair := range pairs {
+					if !policy.Allows(element, pair, ds.TierMap) {
+						c.recordPair(element, pair, element, false, "")
+					}
+				}
+			}
+		}
+
+		// Coba tiap resep yang valid
+		for i := 0; i < len(state.ValidPairs); i++ {
+			pair := state.ValidPairs[i]
+			c.examine(1)
+			c.recordPair(element, pair, element, true, "")
+
+			// Catat resep ini sementara
+			result[element] = Element{
+				Source:  pair.First,
+				Partner: pair.Second,
+			}
+
+			// Coba resolve kedua bahan
+			firstResolved := ds.IsBase(pair.First) || explore(pair.First)
+			if !firstResolved {
+				continue // Coba resep berikutnya kalo bahan pertama gak bisa diresolved
+			}
+
+			secondResolved := ds.IsBase(pair.Second) || explore(pair.Second)
+			if !secondResolved {
+				continue // Coba resep berikutnya kalo bahan kedua gak bisa diresolved
+			}
+
+			// Kedua bahan resolved - kita nemu resep valid
+			state.Visited = true
+			state.CurrentPairIndex = i + 1 // Inget resep mana yang kita pake
+			return true
+		}
+
+		// Kalo sampe sini, gak ada resep valid yang ketemu
+		delete(result, element) // Hapus resep sementara
+		state.Failed = cycleHits == hitsBefore
+		return false
+	}
+
+	// Mulai eksplorasi dari target
+	if !explore(target) {
+		return make(map[string]Element), false
+	}
+
+	// Bersihin map hasil - hapus entri dengan resep kosong yang bukan elemen dasar
+	for key, elem := range result {
+		if !ds.IsBase(key) && (elem.Source == "" || elem.Partner == "") {
+			delete(result, key)
+		}
+	}
+
+	return result, true
+}
+
+// filterByLevel nyisain pasangan yang kedua bahannya punya level derivasi lebih kecil dari element
+func filterByLevel(pairs []Pair, element string, levels map[string]int) []Pair {
+	level, reachable := levels[element]
+	if !reachable {
+		return nil
+	}
+
+	var result []Pair
+	for _, pair := range pairs {
+		first, firstOk := levels[pair.First]
+		second, secondOk := levels[pair.Second]
+		if firstOk && secondOk && first < level && second < level {
+			result = append(result, pair)
+		}
+	}
+	return result
+}
+
+// filterValidPairs nyaring pasangan yang boleh dipake buat bikin element menurut policy
+// (default-nya cuma bahan dari tier lebih rendah, lihat StrictTierPolicy)
+func filterValidPairs(pairs []Pair, element string, tierMap map[string]int, policy ValidityPolicy) []Pair {
+	var validPairs []Pair
+
+	for _, pair := range pairs {
+		if policy.Allows(element, pair, tierMap) {
+			validPairs = append(validPairs, pair)
+		}
+	}
+
+	return validPairs
+}
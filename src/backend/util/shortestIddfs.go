@@ -0,0 +1,145 @@
+package util
+
+// ShortestIddfs nyari resep dengan kedalaman minimal pake iterative deepening DFS:
+// DFS dengan batas kedalaman 1, 2, 3, ... sampai target bisa dibikin. Memorinya
+// cuma sebanding jumlah elemen (hasil per elemen) plus stack sedalam batasnya,
+// gak kayak BFS yang nyimpen seluruh frontier.
+func ShortestIddfs(target string, ds *Dataset, policy ValidityPolicy) map[string]Element {
+	return shortestIddfs(target, ds, policy, nil)
+}
+
+// ShortestIddfsWithStats ShortestIddfs beserta statistik pencariannya
+func ShortestIddfsWithStats(target string, ds *Dataset, policy ValidityPolicy) (map[string]Element, SearchStats) {
+	c := newSearchCounters()
+	recipe := shortestIddfs(target, ds, policy, c)
+	return recipe, c.finish()
+}
+
+// iddfsSearch state ShortestIddfs yang kebawa antar iterasi.
+//
+// depths cuma diisi kedalaman minimal yang udah pasti: DFS di satu elemen nyoba
+// semua pasangannya, dan pasangan terbaik bahannya pasti muat di batas limit-1,
+// jadi kalo hasilnya <= limit berarti itu minimal. failedAt nyimpen batas terbesar
+// yang udah pasti gagal, unreachable elemen yang gagal tanpa kepotong batas.
+type iddfsSearch struct {
+	ds     *Dataset
+	policy ValidityPolicy
+	c      *searchCounters
+
+	depths      map[string]int
+	via         map[string]Pair
+	failedAt    map[string]int
+	unreachable map[string]bool
+	pairs       map[string][]Pair
+	stack       int // Kedalaman rekursi saat ini, buat FrontierPeak
+}
+
+func shortestIddfs(target string, ds *Dataset, policy ValidityPolicy, c *searchCounters) map[string]Element {
+	result := make(map[string]Element)
+	if _, exists := ds.RevCombinations[target]; !exists || ds.IsBase(target) {
+		return result
+	}
+
+	s := &iddfsSearch{
+		ds:          ds,
+		policy:      policy,
+		c:           c,
+		depths:      make(map[string]int),
+		via:         make(map[string]Pair),
+		failedAt:    make(map[string]int),
+		unreachable: make(map[string]bool),
+		pairs:       make(map[string][]Pair),
+	}
+
+	// Resep terdangkal gak mungkin lebih dalam dari jumlah elemen yang bisa dibikin
+	for limit := 1; limit <= len(ds.RevCombinations); limit++ {
+		_, found, cut := s.resolve(target, limit)
+		if found {
+			break
+		}
+		// Gagal tanpa pernah kepotong batas: makin dalam juga gak bakal ketemu
+		if !cut {
+			return result
+		}
+	}
+	if _, found := s.depths[target]; !found {
+		return result
+	}
+
+	stack := []string{target}
+	for len(stack) > 0 {
+		elem := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, done := result[elem]; done || ds.IsBase(elem) {
+			continue
+		}
+		pair := s.via[elem]
+		result[elem] = Element{Source: pair.First, Partner: pair.Second}
+		stack = append(stack, pair.First, pair.Second)
+	}
+	return result
+}
+
+// resolve kedalaman minimal element kalo <= limit. cut true kalo kegagalannya
+// (sebagian) gara-gara batas kedalaman, jadi masih mungkin ketemu di iterasi berikutnya.
+func (s *iddfsSearch) resolve(element string, limit int) (depth int, found bool, cut bool) {
+	if s.ds.IsBase(element) {
+		return 0, true, false
+	}
+	if depth, exists := s.depths[element]; exists {
+		return depth, depth <= limit, depth > limit
+	}
+	if s.unreachable[element] {
+		return 0, false, false
+	}
+	if failed, exists := s.failedAt[element]; limit == 0 || (exists && failed >= limit) {
+		return 0, false, true
+	}
+
+	s.stack++
+	s.c.observeFrontier(s.stack)
+	s.c.expand()
+	s.c.record(TraceEvent{Kind: TraceEnqueue, Element: element})
+	defer s.c.record(TraceEvent{Kind: TraceDequeue, Element: element})
+	defer func() { s.stack-- }()
+
+	pairs, cached := s.pairs[element]
+	if !cached {
+		pairs = uniquePairs(filterValidPairs(s.ds.RevCombinations[element], element, s.ds.TierMap, s.policy))
+		s.pairs[element] = pairs
+		s.c.recordRejected(element, s.ds.RevCombinations[element], s.ds, s.policy)
+	}
+
+	best := unreachableScore
+	var choice Pair
+	for _, pair := range pairs {
+		s.c.examine(1)
+		s.c.recordPair(element, pair, element, true, "")
+		first, firstFound, firstCut := s.resolve(pair.First, limit-1)
+		cut = cut || firstCut
+		if !firstFound {
+			continue
+		}
+		second, secondFound, secondCut := s.resolve(pair.Second, limit-1)
+		cut = cut || secondCut
+		if !secondFound {
+			continue
+		}
+		if value := 1 + max(first, second); value < best {
+			best = value
+			choice = pair
+		}
+	}
+
+	if best < unreachableScore {
+		s.depths[element] = best
+		s.via[element] = choice
+		return best, true, false
+	}
+	if cut {
+		s.failedAt[element] = limit
+	} else {
+		s.unreachable[element] = true
+	}
+	return 0, false, cut
+}
@@ -0,0 +1,121 @@
+package util
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SearchStats statistik eksplorasi satu pencarian. Beda sama NodeCount (yang
+// isinya cuma elemen di resep yang ketemu), angka di sini ngukur kerjaan
+// algoritmanya, jadi bisa dipake buat ngebandingin BFS, DFS, dkk.
+type SearchStats struct {
+	NodesExpanded      int64         `json:"nodesExpanded"`      // Elemen/state yang diekspansi
+	EdgesExamined      int64         `json:"edgesExamined"`      // Kombinasi yang dicek
+	FrontierPeak       int           `json:"frontierPeak"`       // Ukuran queue/stack terbesar
+	DuplicatesRejected int64         `json:"duplicatesRejected"` // Resep yang dibuang karena udah pernah ketemu
+	SubSearches        int64         `json:"subSearches"`        // Pencarian kecil di dalam pencarian (resep awal, resep bahan, perbaikan)
+	Workers            []WorkerStats `json:"workers,omitempty"`  // Per worker, cuma buat algoritma paralel
+	WallTime           time.Duration `json:"wallTimeNs"`
+	CPUTime            time.Duration `json:"cpuTimeNs"` // CPU time proses, ikut kehitung request lain yang jalan barengan
+}
+
+// WorkerStats kerjaan satu worker di pencarian paralel
+type WorkerStats struct {
+	Batches       int64 `json:"batches"`
+	NodesExpanded int64 `json:"nodesExpanded"`
+	RecipesFound  int64 `json:"recipesFound"`
+}
+
+// searchCounters penghitung SearchStats yang aman dipake bareng beberapa goroutine.
+// Semua method-nya boleh dipanggil di pointer nil (gak ngitung apa-apa), jadi
+// helper yang sama bisa dipake dengan atau tanpa statistik.
+type searchCounters struct {
+	expanded     atomic.Int64
+	edges        atomic.Int64
+	duplicates   atomic.Int64
+	subSearches  atomic.Int64
+	frontierPeak atomic.Int64
+
+	start    time.Time
+	startCPU time.Duration
+
+	trace *SearchTrace // Nil kalo gak lagi nge-trace; cuma dipake pencarian satu goroutine
+}
+
+func newSearchCounters() *searchCounters {
+	return &searchCounters{start: time.Now(), startCPU: processCPUTime()}
+}
+
+func (c *searchCounters) expand() {
+	if c != nil {
+		c.expanded.Add(1)
+	}
+}
+
+func (c *searchCounters) examine(edges int) {
+	if c != nil {
+		c.edges.Add(int64(edges))
+	}
+}
+
+func (c *searchCounters) duplicate() {
+	if c != nil {
+		c.duplicates.Add(1)
+	}
+}
+
+func (c *searchCounters) subSearch() {
+	if c != nil {
+		c.subSearches.Add(1)
+	}
+}
+
+// tracing true kalo event perlu dicatet, biar event gak dirakit percuma
+func (c *searchCounters) tracing() bool {
+	return c != nil && c.trace != nil
+}
+
+// record nyatet event ke trace (kalo ada)
+func (c *searchCounters) record(event TraceEvent) {
+	if c.tracing() {
+		c.trace.record(event)
+	}
+}
+
+// recordPair nyatet kombinasi yang dicoba: expand-pair kalo diizinin, tier-reject kalo gak
+func (c *searchCounters) recordPair(element string, pair Pair, product string, allowed bool, direction string) {
+	if !c.tracing() {
+		return
+	}
+	kind := TraceExpandPair
+	if !allowed {
+		kind = TraceTierReject
+	}
+	c.trace.record(TraceEvent{Kind: kind, Element: element, First: pair.First, Second: pair.Second, Product: product, Direction: direction})
+}
+
+// observeFrontier nyatet ukuran frontier, yang kecatet cuma yang terbesar
+func (c *searchCounters) observeFrontier(size int) {
+	if c == nil {
+		return
+	}
+	for {
+		peak := c.frontierPeak.Load()
+		if int64(size) <= peak || c.frontierPeak.CompareAndSwap(peak, int64(size)) {
+			return
+		}
+	}
+}
+
+// finish ngambil hasil akhir, waktu dihitung dari newSearchCounters
+func (c *searchCounters) finish() SearchStats {
+	return SearchStats{
+		NodesExpanded:      c.expanded.Load(),
+		EdgesExamined:      c.edges.Load(),
+		FrontierPeak:       int(c.frontierPeak.Load()),
+		DuplicatesRejected: c.duplicates.Load(),
+		SubSearches:        c.subSearches.Load(),
+		WallTime:           time.Since(c.start),
+		CPUTime:            processCPUTime() - c.startCPU,
+	}
+}
@@ -0,0 +1,155 @@
+package util
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// TraceEventKind jenis event di SearchTrace
+type TraceEventKind string
+
+const (
+	TraceEnqueue    TraceEventKind = "enqueue"     // Elemen masuk queue/stack
+	TraceDequeue    TraceEventKind = "dequeue"     // Elemen keluar queue/stack buat diekspansi
+	TraceExpandPair TraceEventKind = "expand-pair" // Kombinasi dicoba dan diizinin policy
+	TraceTierReject TraceEventKind = "tier-reject" // Kombinasi ada di data tapi ditolak policy
+	TraceMeet       TraceEventKind = "meet"        // Pencarian maju dan mundur ketemu (bidirectional)
+)
+
+// Arah pencarian di event bidirectional
+const (
+	TraceForward  = "forward"
+	TraceBackward = "backward"
+)
+
+// DefaultTraceLimit batas event default biar trace target yang dalam gak makan memori
+const DefaultTraceLimit = 10000
+
+// TraceEvent satu langkah pencarian. First/Second/Product cuma diisi di event kombinasi.
+type TraceEvent struct {
+	Seq       int            `json:"seq"`
+	Kind      TraceEventKind `json:"kind"`
+	Element   string         `json:"element"`
+	First     string         `json:"first,omitempty"`
+	Second    string         `json:"second,omitempty"`
+	Product   string         `json:"product,omitempty"`
+	Direction string         `json:"direction,omitempty"`
+}
+
+// SearchTrace log event pencarian yang urut, dibatesin Limit. Event yang lewat
+// batas gak disimpen, cuma dihitung di Dropped.
+type SearchTrace struct {
+	Limit     int          `json:"limit"`
+	Events    []TraceEvent `json:"events"`
+	Truncated bool         `json:"truncated"`
+	Dropped   int          `json:"dropped"`
+}
+
+// NewSearchTrace bikin trace kosong, limit <= 0 pake DefaultTraceLimit
+func NewSearchTrace(limit int) *SearchTrace {
+	if limit <= 0 {
+		limit = DefaultTraceLimit
+	}
+	return &SearchTrace{Limit: limit, Events: []TraceEvent{}}
+}
+
+func (t *SearchTrace) record(event TraceEvent) {
+	if len(t.Events) >= t.Limit {
+		t.Truncated = true
+		t.Dropped++
+		return
+	}
+	event.Seq = len(t.Events)
+	t.Events = append(t.Events, event)
+}
+
+// TraceSummary baris terakhir JSONL, biar log yang kepotong bisa dibedain dari yang lengkap
+type TraceSummary struct {
+	Kind      string `json:"kind"` // Selalu "summary"
+	Events    int    `json:"events"`
+	Limit     int    `json:"limit"`
+	Truncated bool   `json:"truncated"`
+	Dropped   int    `json:"dropped"`
+}
+
+// WriteJSONL nulis satu event per baris, buat di-download terus di-replay.
+// Baris terakhir TraceSummary.
+func (t *SearchTrace) WriteJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, event := range t.Events {
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+	}
+	return enc.Encode(TraceSummary{
+		Kind:      "summary",
+		Events:    len(t.Events),
+		Limit:     t.Limit,
+		Truncated: t.Truncated,
+		Dropped:   t.Dropped,
+	})
+}
+
+// Varian *Trace di bawah nyatet tiap langkah pencarian ke trace. found false
+// kalo target gak bisa dibikin, jadi caller gak perlu ngecek ulang.
+
+// ShortestBfsTrace ShortestBfs yang nyatet tiap langkahnya ke trace
+func ShortestBfsTrace(target string, ds *Dataset, policy ValidityPolicy, trace *SearchTrace) (map[string]Element, bool, SearchStats) {
+	c := newSearchCounters()
+	c.trace = trace
+	recipe, found := shortestBfs(target, ds, policy, c)
+	return recipe, found, c.finish()
+}
+
+// ShortestDfsTrace ShortestDfs yang nyatet tiap langkahnya ke trace
+func ShortestDfsTrace(target string, ds *Dataset, policy ValidityPolicy, trace *SearchTrace) (map[string]Element, bool, SearchStats) {
+	c := newSearchCounters()
+	c.trace = trace
+	recipe, found := shortestDfs(target, ds, policy, c)
+	return recipe, found, c.finish()
+}
+
+// ShortestBidirectionalTrace ShortestBidirectional yang nyatet tiap langkahnya ke trace
+func ShortestBidirectionalTrace(target string, ds *Dataset, policy ValidityPolicy, trace *SearchTrace) (map[string]Element, bool, SearchStats) {
+	c := newSearchCounters()
+	c.trace = trace
+	recipe := shortestBidirectional(target, ds, policy, c)
+	return recipe, len(recipe) > 0, c.finish()
+}
+
+// ShortestIddfsTrace ShortestIddfs yang nyatet tiap langkahnya ke trace
+func ShortestIddfsTrace(target string, ds *Dataset, policy ValidityPolicy, trace *SearchTrace) (map[string]Element, bool, SearchStats) {
+	c := newSearchCounters()
+	c.trace = trace
+	recipe := shortestIddfs(target, ds, policy, c)
+	return recipe, len(recipe) > 0, c.finish()
+}
+
+// ShortestAStarTrace ShortestAStar yang nyatet tiap langkahnya ke trace
+func ShortestAStarTrace(target string, ds *Dataset, policy ValidityPolicy, trace *SearchTrace) (map[string]Element, bool, SearchStats) {
+	c := newSearchCounters()
+	c.trace = trace
+	recipe := shortestAStar(target, ds, policy, c)
+	return recipe, len(recipe) > 0, c.finish()
+}
+
+// BeamSearchTrace BeamSearch yang nyatet tiap langkahnya ke trace
+func BeamSearchTrace(target string, ds *Dataset, policy ValidityPolicy, width int, trace *SearchTrace) (map[string]Element, bool, SearchStats) {
+	c := newSearchCounters()
+	c.trace = trace
+	recipe := beamSearch(target, ds, policy, width, c)
+	return recipe, len(recipe) > 0, c.finish()
+}
+
+// recordRejected nyatet pasangan element yang ditolak policy (buat algoritma yang
+// cuma ngeliat pasangan hasil filterValidPairs)
+func (c *searchCounters) recordRejected(element string, pairs []Pair, ds *Dataset, policy ValidityPolicy) {
+	if !c.tracing() {
+		return
+	}
+	for _, pair := range pairs {
+		if !policy.Allows(element, pair, ds.TierMap) {
+			c.recordPair(element, pair, element, false, "")
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package util_test
+
+import (
+	"testing"
+
+	"backend/util"
+)
+
+// Semua algoritma di registry harus bisa di-trace, found-nya gak bohong,
+// dan resep yang dikembaliin valid
+func TestTraceSearchers(t *testing.T) {
+	ds := fixtureDataset()
+	for _, info := range util.SearcherInfos() {
+		searcher, err := util.SearcherByName(info.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tracer, ok := searcher.(util.TraceSearcher)
+		if !ok || !info.Capabilities.Trace {
+			t.Errorf("%s: not a TraceSearcher (capability %v)", info.Name, info.Capabilities.Trace)
+			continue
+		}
+		for _, policy := range testPolicies {
+			for _, target := range sortedElements(ds) {
+				if ds.IsBase(target) {
+					continue
+				}
+				trace := util.NewSearchTrace(0)
+				recipe, found, _ := tracer.SearchTrace(target, ds, policy, util.SearchOptions{}, trace)
+				want := bruteForceDepth(ds, policy, target)
+				// BFS maju lewat Combinations (satu produk per pair), jadi cuma algoritma
+				// Optimal yang dijamin nemu semua target yang bisa dibikin
+				if (found && want < 0) || (!found && want >= 0 && info.Capabilities.Optimal) {
+					t.Errorf("%s: %s (%s): found = %v, brute force depth %d", info.Name, target, policy.Name(), found, want)
+					continue
+				}
+				if !found {
+					if len(recipe) > 0 {
+						t.Errorf("%s: %s (%s): not found but returned %v", info.Name, target, policy.Name(), recipe)
+					}
+					continue
+				}
+				checkRecipe(t, ds, policy, target, recipe)
+				if info.Capabilities.Optimal {
+					checkOptimal(t, ds, policy, target, recipe, want)
+				}
+				if len(trace.Events) == 0 {
+					t.Errorf("%s: %s (%s): no trace events", info.Name, target, policy.Name())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VerifiedNode node pohon resep yang udah dicek, bentuknya sama kayak Node
+// ditambah status valid dan daftar masalah di node itu
+type VerifiedNode struct {
+	Name     string          `json:"name"`
+	Valid    bool            `json:"valid"`
+	Errors   []string        `json:"errors,omitempty"`
+	Children []*VerifiedNode `json:"children,omitempty"`
+}
+
+// VerifyResult hasil VerifyTree
+type VerifyResult struct {
+	Valid      bool          `json:"valid"`
+	ErrorCount int           `json:"errorCount"`
+	Tree       *VerifiedNode `json:"tree"`
+}
+
+// VerifyTree ngecek pohon resep kiriman user: tiap kombinasi harus ada di
+// combinations, kombinasinya harus diizinkan policy (default: bahan dari tier
+// lebih rendah), daun harus elemen dasar, dan gak boleh ada elemen yang butuh dirinya sendiri.
+// Di dataset yang punya reaction, node boleh punya berapa pun bahan asal cocok sama salah satu reaction.
+func VerifyTree(root *Node, ds *Dataset, policy ValidityPolicy) VerifyResult {
+	result := VerifyResult{}
+	if root == nil {
+		result.Tree = &VerifiedNode{Errors: []string{"empty tree"}}
+		result.ErrorCount = 1
+		return result
+	}
+
+	// Elemen di jalur dari akar ke node saat ini, buat deteksi siklus
+	ancestors := make(map[string]bool)
+
+	var verify func(node *Node) *VerifiedNode
+	verify = func(node *Node) *VerifiedNode {
+		checked := &VerifiedNode{Name: node.Name}
+		addError := func(format string, args ...any) {
+			checked.Errors = append(checked.Errors, fmt.Sprintf(format, args...))
+		}
+
+		_, known := ds.TierMap[node.Name]
+		switch {
+		case node.Name == "":
+			addError("node has no name")
+		case !known:
+			addError("unknown element %q", node.Name)
+		}
+
+		if ancestors[node.Name] {
+			// Jangan turun lebih dalam, nanti gak selesai-selesai
+			addError("cycle: %s is needed to make itself", node.Name)
+			result.ErrorCount += len(checked.Errors)
+			return checked
+		}
+
+		ingredients := make([]string, 0, len(node.Children))
+		for _, child := range node.Children {
+			if child != nil {
+				ingredients = append(ingredients, child.Name)
+			}
+		}
+
+		switch {
+		case len(node.Children) == 0:
+			if known && !ds.IsBase(node.Name) {
+				addError("%s is not a base element but has no ingredients", node.Name)
+			}
+		case len(ingredients) < len(node.Children):
+			// Bahan yang null dilaporin "missing ingredient" di loop anak di bawah
+		case len(ingredients) == 2:
+			first, second := ingredients[0], ingredients[1]
+			product, exists := ds.Combinations[Pair{First: first, Second: second}]
+			if !exists {
+				product, exists = ds.Combinations[Pair{First: second, Second: first}]
+			}
+			if (!exists || product != node.Name) && ds.HasReactions() {
+				// Bisa jadi reaction dua bahan yang ngasilin beberapa produk
+				if reaction, matched := matchReaction(ds, node.Name, ingredients); matched {
+					if !allowsReaction(policy, node.Name, reaction, ds.TierMap) {
+						addError("%s is not allowed by the %s policy for %s (tier %d)",
+							strings.Join(ingredients, " + "), policy.Name(), node.Name, ds.TierMap[node.Name])
+					}
+					break
+				}
+			}
+			switch {
+			case !exists:
+				addError("%s + %s is not a valid combination", first, second)
+			case product != node.Name:
+				addError("%s + %s makes %s, not %s", first, second, product, node.Name)
+			case !policy.Allows(node.Name, Pair{First: first, Second: second}, ds.TierMap):
+				addError("%s + %s is not allowed by the %s policy for %s (tier %d)",
+					first, second, policy.Name(), node.Name, ds.TierMap[node.Name])
+			}
+		case ds.HasReactions():
+			reaction, matched := matchReaction(ds, node.Name, ingredients)
+			switch {
+			case !matched:
+				addError("%s is not a valid reaction for %s", strings.Join(ingredients, " + "), node.Name)
+			case !allowsReaction(policy, node.Name, reaction, ds.TierMap):
+				addError("%s is not allowed by the %s policy for %s (tier %d)",
+					strings.Join(ingredients, " + "), policy.Name(), node.Name, ds.TierMap[node.Name])
+			}
+		default:
+			addError("%s has %d ingredients, expected 2", node.Name, len(node.Children))
+		}
+
+		result.ErrorCount += len(checked.Errors)
+
+		ancestors[node.Name] = true
+		for _, child := range node.Children {
+			if child == nil {
+				checked.Children = append(checked.Children, &VerifiedNode{Errors: []string{"missing ingredient"}})
+				result.ErrorCount++
+				continue
+			}
+			checked.Children = append(checked.Children, verify(child))
+		}
+		delete(ancestors, node.Name)
+
+		checked.Valid = len(checked.Errors) == 0
+		for _, child := range checked.Children {
+			checked.Valid = checked.Valid && child.Valid
+		}
+		return checked
+	}
+
+	result.Tree = verify(root)
+	result.Valid = result.ErrorCount == 0
+	return result
+}
+
+// matchReaction nyari reaction yang ngasilin product dari persis ingredients (urutan bebas)
+func matchReaction(ds *Dataset, product string, ingredients []string) (Reaction, bool) {
+	sorted := func(names []string) string {
+		names = append([]string(nil), names...)
+		sort.Strings(names)
+		return strings.Join(names, "+")
+	}
+	want := sorted(ingredients)
+	for _, reaction := range ds.ReactionsFor(product) {
+		if sorted(reaction.Ingredients) == want {
+			return reaction, true
+		}
+	}
+	return Reaction{}, false
+}
@@ -0,0 +1,75 @@
+package util
+
+import "testing"
+
+func TestVerifyTreeNilIngredient(t *testing.T) {
+	ds := NewDataset("test", []string{"Air", "Fire"},
+		map[Pair]string{{First: "Air", Second: "Fire"}: "Smoke", {First: "Fire", Second: "Air"}: "Smoke"},
+		map[string][]Pair{"Smoke": {{First: "Air", Second: "Fire"}}},
+		map[string]int{"Air": 0, "Fire": 0, "Smoke": 1})
+
+	for _, children := range [][]*Node{{nil, {Name: "Fire"}}, {{Name: "Air"}, nil}, {nil, nil}} {
+		result := VerifyTree(&Node{Name: "Smoke", Children: children}, ds, DefaultPolicy)
+		if result.Valid {
+			t.Fatalf("tree with a nil ingredient reported valid")
+		}
+		missing := 0
+		for _, child := range result.Tree.Children {
+			for _, err := range child.Errors {
+				if err == "missing ingredient" {
+					missing++
+				}
+			}
+		}
+		if want := len(children) - countNonNil(children); missing != want {
+			t.Errorf("got %d missing ingredient errors, want %d", missing, want)
+		}
+	}
+}
+
+func countNonNil(nodes []*Node) int {
+	n := 0
+	for _, node := range nodes {
+		if node != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func TestVerifyTreeReactions(t *testing.T) {
+	ds := NewDataset("reactions", []string{"Air", "Fire", "Water"}, map[Pair]string{}, map[string][]Pair{},
+		map[string]int{"Air": 0, "Fire": 0, "Water": 0, "Steam": 1, "Smoke": 1, "Cloud": 2})
+	ds.AddReaction(Reaction{Ingredients: []string{"Fire", "Water"}, Products: []string{"Steam", "Smoke"}})
+	ds.AddReaction(Reaction{Ingredients: []string{"Air", "Steam", "Water"}, Products: []string{"Cloud"}})
+
+	// Semua tree hasil MultipleReactions harus lolos
+	found := MultipleReactions("Cloud", ds, DefaultPolicy, 5)
+	trees, _ := BuildMultipleReactionTrees("Cloud", found)
+	if len(trees) == 0 {
+		t.Fatalf("MultipleReactions found no recipe for Cloud")
+	}
+	for _, tree := range trees {
+		if result := VerifyTree(tree, ds, DefaultPolicy); !result.Valid {
+			t.Errorf("reaction tree reported invalid: %+v", result.Tree)
+		}
+	}
+
+	// Urutan bahan bebas; bahan yang salah tetep ketahuan
+	leaf := func(name string) *Node { return &Node{Name: name} }
+	steam := &Node{Name: "Steam", Children: []*Node{leaf("Water"), leaf("Fire")}}
+	valid := &Node{Name: "Cloud", Children: []*Node{leaf("Water"), steam, leaf("Air")}}
+	if result := VerifyTree(valid, ds, DefaultPolicy); !result.Valid {
+		t.Errorf("reordered reaction tree reported invalid: %+v", result.Tree)
+	}
+	invalid := &Node{Name: "Cloud", Children: []*Node{leaf("Fire"), steam, leaf("Air")}}
+	if result := VerifyTree(invalid, ds, DefaultPolicy); result.Valid || len(result.Tree.Errors) != 1 {
+		t.Errorf("wrong reaction: valid %v, errors %v", result.Valid, result.Tree.Errors)
+	}
+
+	// Dataset tanpa reaction tetep cuma nerima dua bahan
+	pairs := NewDataset("pairs", []string{"Air", "Fire", "Water"}, map[Pair]string{}, map[string][]Pair{}, ds.TierMap)
+	if result := VerifyTree(valid, pairs, DefaultPolicy); result.Valid {
+		t.Errorf("three ingredients accepted in a dataset without reactions")
+	}
+}
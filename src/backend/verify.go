@@ -0,0 +1,38 @@
+package main
+
+import (
+	"backend/util"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// verifyHandler ngecek pohon resep (bentuk util.Node, sama kayak treeData)
+// dan ngembaliin pohon yang sama dengan anotasi error per node.
+// Policy dan dataset bisa dipilih lewat query ?policy= dan ?dataset=, default strict-tier dan la2.
+func verifyHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	policy, err := util.PolicyByName(r.URL.Query().Get("policy"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ds, err := datasetByName(r.URL.Query().Get("dataset"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var tree util.Node
+	if err := json.NewDecoder(r.Body).Decode(&tree); err != nil {
+		log.Println("JSON decode error:", err)
+		http.Error(w, "Invalid JSON input", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, util.VerifyTree(&tree, ds, policy))
+}
@@ -8,6 +8,14 @@ import multiple from '../media/icons/multiple.svg';
 import biBFS from '../media/icons/biBFS.svg';
 import talenanBg from '../media/talenan.png';
 
+// Ikon algoritma bawaan; algoritma lain dari /api/algorithms pake ikon sesuai mode-nya
+const algorithmIcons = { 'BFS': BFS, 'DFS': DFS, 'Bi-BFS': biBFS };
+const defaultAlgorithms = [
+  { name: 'BFS', icon: BFS },
+  { name: 'DFS', icon: DFS },
+  { name: 'Bi-BFS', icon: biBFS },
+];
+
 const About = () => {
   const { register, handleSubmit, setValue, trigger } = useForm({ mode: "onChange" });
   const [treeDataList, setTreeDataList] = useState([]);
@@ -17,6 +25,19 @@ const About = () => {
   const [selectedAlgorithm, setSelectedAlgorithm] = useState(null);
   const [selectedSearchMode, setSelectedSearchMode] = useState(null);
   const [isJumlahResepDisabled, setIsJumlahResepDisabled] = useState(false);
+  const [algorithms, setAlgorithms] = useState(defaultAlgorithms);
+
+  useEffect(() => {
+    fetch("http://localhost:8080/api/algorithms")
+      .then((response) => (response.ok ? response.json() : Promise.reject(response.statusText)))
+      .then((data) => {
+        setAlgorithms(data.algorithms.map((algorithm) => ({
+          name: algorithm.name,
+          icon: algorithmIcons[algorithm.name] || (algorithm.capabilities.multiple ? multiple : single),
+        })));
+      })
+      .catch((err) => console.error("Failed to fetch algorithms:", err));
+  }, []);
 
   useEffect(() => {
     if (selectedSearchMode === "Single") {
@@ -166,11 +187,7 @@ const About = () => {
           <div className="Search-form-card">
             <h3>Algoritma*</h3>
             <OptionsButton
-              options={[
-                { name: 'BFS', icon: BFS },
-                { name: 'DFS', icon: DFS },
-                { name: 'Bi-BFS', icon: biBFS },
-              ]}
+              options={algorithms}
               selectedOption={selectedAlgorithm}
               setSelectedOption={setSelectedAlgorithm}
               dataType="algoritma"